@@ -0,0 +1,144 @@
+package zeroformatter
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shamaton/zeroformatter/datetimeoffset"
+)
+
+// SerializeCanonical analyzes holder and converts to byte datas, with writing map entries in the order of keys.
+// It is as same as Serialize with WithCanonical.
+func SerializeCanonical(holder interface{}, opts ...Option) ([]byte, error) {
+	return Serialize(holder, append(opts, WithCanonical())...)
+}
+
+// sortEntries sorts map keys by compareKeys, and values are moved with them.
+func sortEntries(keys, values []reflect.Value) {
+	sort.Sort(mapEntries{keys: keys, values: values})
+}
+
+type mapEntries struct {
+	keys, values []reflect.Value
+}
+
+func (m mapEntries) Len() int           { return len(m.keys) }
+func (m mapEntries) Less(i, j int) bool { return compareKeys(m.keys[i], m.keys[j]) < 0 }
+func (m mapEntries) Swap(i, j int) {
+	m.keys[i], m.keys[j] = m.keys[j], m.keys[i]
+	m.values[i], m.values[j] = m.values[j], m.values[i]
+}
+
+// compareKeys returns -1, 0 or 1 by the order of a and b, which have the same type.
+//
+// Numbers, char.Char, time.Duration and enums are ordered by the value. NaN is the first, and -0 is after +0.
+// Strings are ordered by bytes, and false is before true. time.Time is ordered by the instant,
+// and datetimeoffset.DateTimeOffset is ordered by the instant, then by the offset.
+// Arrays and structs are ordered by elements and fields in order. Nil pointers are the first.
+// Keys whose order is 0 are always serialized to the same bytes.
+func compareKeys(a, b reflect.Value) int {
+	switch a.Type() {
+	case dateTimeType:
+		return a.Interface().(time.Time).Compare(b.Interface().(time.Time))
+
+	case dateTimeOffsetType:
+		ta, tb := a.Interface().(datetimeoffset.DateTimeOffset), b.Interface().(datetimeoffset.DateTimeOffset)
+		if c := ta.Compare(tb.Time); c != 0 {
+			return c
+		}
+		return compareInt(int64(ta.OffsetMinutes()), int64(tb.OffsetMinutes()))
+	}
+
+	switch a.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		return compareInt(a.Int(), b.Int())
+
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		x, y := a.Uint(), b.Uint()
+		if x < y {
+			return -1
+		} else if x > y {
+			return 1
+		}
+		return 0
+
+	case reflect.Float32, reflect.Float64:
+		return compareFloat(a.Float(), b.Float())
+
+	case reflect.String:
+		return strings.Compare(a.String(), b.String())
+
+	case reflect.Bool:
+		if a.Bool() == b.Bool() {
+			return 0
+		} else if !a.Bool() {
+			return -1
+		}
+		return 1
+
+	case reflect.Array:
+		for i := 0; i < a.Len(); i++ {
+			if c := compareKeys(a.Index(i), b.Index(i)); c != 0 {
+				return c
+			}
+		}
+		return 0
+
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if c := compareKeys(a.Field(i), b.Field(i)); c != 0 {
+				return c
+			}
+		}
+		return 0
+
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			return compareInt(boolInt(!a.IsNil()), boolInt(!b.IsNil()))
+		}
+		return compareKeys(a.Elem(), b.Elem())
+	}
+	return 0
+}
+
+func compareInt(x, y int64) int {
+	if x < y {
+		return -1
+	} else if x > y {
+		return 1
+	}
+	return 0
+}
+
+func compareFloat(x, y float64) int {
+	nx, ny := math.IsNaN(x), math.IsNaN(y)
+	switch {
+	case nx && ny:
+	case nx:
+		return -1
+	case ny:
+		return 1
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	// NaNs, and +0 and -0 are ordered by bits
+	bx, by := math.Float64bits(x), math.Float64bits(y)
+	if bx < by {
+		return -1
+	} else if bx > by {
+		return 1
+	}
+	return 0
+}
+
+func boolInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -1,5 +1,54 @@
 package char
 
-// todo : write why this type should exist.
+import "unicode/utf16"
 
+// Char is a UTF-16 code unit, which is C# char.
+// rune in golang is a unicode code point and serialized as Int32,
+// so this type is used to serialize as Char (2 bytes).
+//
+// A character out of BMP needs 2 code units (surrogate pair) in UTF-16,
+// so it can not be a single Char. Use FromString and ToString for C# char[].
 type Char rune
+
+const (
+	// MaxChar is the maximum value of a UTF-16 code unit.
+	MaxChar = Char(0xFFFF)
+
+	// ReplacementChar is used instead of invalid characters.
+	ReplacementChar = Char(0xFFFD)
+)
+
+// IsValid reports whether c fits in a single UTF-16 code unit.
+// Surrogates are valid because they can be parts of a surrogate pair.
+func (c Char) IsValid() bool {
+	return 0 <= c && c <= MaxChar
+}
+
+// IsSurrogate reports whether c is a part of a surrogate pair.
+func (c Char) IsSurrogate() bool {
+	return utf16.IsSurrogate(rune(c))
+}
+
+// FromString converts s to UTF-16 code units.
+// Characters out of BMP become surrogate pairs and invalid UTF-8 becomes ReplacementChar.
+func FromString(s string) []Char {
+	u16s := utf16.Encode([]rune(s))
+	cs := make([]Char, len(u16s))
+	for i, u := range u16s {
+		cs[i] = Char(u)
+	}
+	return cs
+}
+
+// ToString converts UTF-16 code units to string.
+// Surrogate pairs are combined and unpaired surrogates become ReplacementChar.
+func ToString(cs []Char) string {
+	u16s := make([]uint16, len(cs))
+	for i, c := range cs {
+		if !c.IsValid() {
+			c = ReplacementChar
+		}
+		u16s[i] = uint16(c)
+	}
+	return string(utf16.Decode(u16s))
+}
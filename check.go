@@ -0,0 +1,81 @@
+package zeroformatter
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Problem is a reason why the value at the path can not be serialized.
+type Problem struct {
+	// Path is the place of the problem, such as "main.Struct.Children[].Func".
+	// [] means elements, [key] and [value] mean keys and values of map.
+	Path   string
+	Reason string
+
+	// NilRisk is true when the type is supported, but the value can be nil.
+	// Serializing nil pointer returns an error.
+	NilRisk bool
+}
+
+func (p Problem) Error() string {
+	return fmt.Sprint(p.Path, " : ", p.Reason)
+}
+
+// CheckError has all problems which Check found.
+type CheckError struct {
+	Problems []Problem
+}
+
+func (e *CheckError) Error() string {
+	msgs := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		msgs[i] = p.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unsupported returns problems which are not NilRisk.
+func (e *CheckError) Unsupported() []Problem {
+	ret := []Problem{}
+	for _, p := range e.Problems {
+		if !p.NilRisk {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
+
+// Check walks t statically, and reports all fields which are not supported and can be nil.
+// Values are not needed, so types in empty slices and maps are checked too.
+// A pointer to its own struct, such as Next *Node, is not supported, because the last one is always nil.
+// It is supported through slices and maps, such as Children []*Node, which can be empty.
+// If no problem is found, nil is returned. Otherwise, the error is *CheckError.
+//
+//	func TestSchema(t *testing.T) {
+//		if err := zeroformatter.Check(reflect.TypeOf(Message{})); err != nil {
+//			t.Error(err)
+//		}
+//	}
+func Check(t reflect.Type) error {
+	if t == nil {
+		return &CheckError{Problems: []Problem{{Path: "nil", Reason: "type is nil"}}}
+	}
+	problems := checkType(t)
+	if len(problems) > 0 {
+		return &CheckError{Problems: problems}
+	}
+	return nil
+}
+
+// MustRegister checks T and creates a codec of T. It panics if T has types which are not supported.
+// Pointers which can be nil are allowed. It is for checking types at init.
+//
+//	var messageCodec = zeroformatter.MustRegister[Message]()
+func MustRegister[T any](opts ...Option) *Codec[T] {
+	c, err := NewCodec[T](opts...)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
@@ -0,0 +1,100 @@
+// Command zfconv converts byte data of ZeroFormatter to JSON, and JSON to byte data, by the schema file.
+//
+// Usage:
+//
+//	zfconv -schema file -object name [-reverse] [-indent] [-int64] [file]
+//
+// If file is not set, input is read from stdin. The mapping of JSON is as same as zeroformatter.ToJSON.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/shamaton/zeroformatter"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("zfconv", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	schemaFile := fs.String("schema", "", "schema file")
+	object := fs.String("object", "", "object name in the schema")
+	reverse := fs.Bool("reverse", false, "convert JSON to byte data")
+	indent := fs.Bool("indent", false, "indent JSON")
+	useInt64 := fs.Bool("int64", false, "int and uint are serialized as Int64 and UInt64")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *schemaFile == "" || *object == "" {
+		fmt.Fprintln(stderr, "-schema and -object are required")
+		fs.Usage()
+		return 2
+	}
+
+	text, err := os.ReadFile(*schemaFile)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	s, err := zeroformatter.ParseSchema(string(text))
+	if err != nil {
+		fmt.Fprintf(stderr, "%s : %v\n", *schemaFile, err)
+		return 2
+	}
+	t, ok := s.Type(*object)
+	if !ok {
+		fmt.Fprintf(stderr, "object is not defined : %s, defined objects are %v\n", *object, s.Names())
+		return 2
+	}
+
+	var in io.Reader = stdin
+	if fs.NArg() > 0 {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		defer f.Close()
+		in = f
+	}
+	data, err := io.ReadAll(in)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	opts := []zeroformatter.Option{}
+	if *useInt64 {
+		opts = append(opts, zeroformatter.WithInt64())
+	}
+
+	var out []byte
+	if *reverse {
+		out, err = zeroformatter.FromJSON(data, t, opts...)
+	} else {
+		out, err = zeroformatter.ToJSON(data, t, opts...)
+		if err == nil && *indent {
+			b := &bytes.Buffer{}
+			err = json.Indent(b, out, "", "  ")
+			out = b.Bytes()
+		}
+		out = append(out, '\n')
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if _, err := stdout.Write(out); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,86 @@
+// Command zfdump prints serialized byte data as an annotated tree, with the schema file or without types.
+//
+// Usage:
+//
+//	zfdump [-schema file -object name] [-int64] [file]
+//
+// If file is not set, byte data is read from stdin.
+// Without -schema, the object header and byte data of each field are printed.
+// The exit code is 1 if malformed regions are found, 2 if args or input are wrong.
+// For golang types, please use zeroformatter.Dump.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+
+	"github.com/shamaton/zeroformatter"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("zfdump", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	schemaFile := fs.String("schema", "", "schema file")
+	object := fs.String("object", "", "object name in the schema")
+	useInt64 := fs.Bool("int64", false, "int and uint are serialized as Int64 and UInt64")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var t reflect.Type
+	if *schemaFile != "" {
+		text, err := os.ReadFile(*schemaFile)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		s, err := zeroformatter.ParseSchema(string(text))
+		if err != nil {
+			fmt.Fprintf(stderr, "%s : %v\n", *schemaFile, err)
+			return 2
+		}
+		var ok bool
+		t, ok = s.Type(*object)
+		if !ok {
+			fmt.Fprintf(stderr, "object is not defined : %s, defined objects are %v\n", *object, s.Names())
+			return 2
+		}
+	} else if *object != "" {
+		fmt.Fprintln(stderr, "-object needs -schema")
+		fs.Usage()
+		return 2
+	}
+
+	var in io.Reader = stdin
+	if fs.NArg() > 0 {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		defer f.Close()
+		in = f
+	}
+	data, err := io.ReadAll(in)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	opts := []zeroformatter.Option{}
+	if *useInt64 {
+		opts = append(opts, zeroformatter.WithInt64())
+	}
+	if err := zeroformatter.Dump(stdout, data, t, opts...); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,106 @@
+package zeroformatter
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Marshal converts v to byte datas.
+func Marshal[T any](v T, opts ...Option) ([]byte, error) {
+	rv, err := holderValue(reflect.ValueOf(&v).Elem())
+	if err != nil {
+		return nil, err
+	}
+	return createSerializer(createOptions(opts)).serializeHolder(rv)
+}
+
+// Unmarshal analyzes byte data and returns it as T.
+func Unmarshal[T any](data []byte, opts ...Option) (T, error) {
+	var v T
+	err := createDeserializer(data, createOptions(opts)).deserializeHolder(allocHolder(reflect.ValueOf(&v).Elem()))
+	return v, err
+}
+
+// holderValue dereferences rv if rv is pointer.
+func holderValue(rv reflect.Value) (reflect.Value, error) {
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return rv, fmt.Errorf("pointer is null : %v", rv.Type())
+		}
+		rv = rv.Elem()
+	}
+	return rv, nil
+}
+
+// allocHolder dereferences rv if rv is pointer, and allocates it if it is nil.
+func allocHolder(rv reflect.Value) reflect.Value {
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	return rv
+}
+
+// Codec serializes and deserializes T.
+// T is checked when the codec is created, and sizes and offsets of fields are kept for repeated use.
+// Codec is safe for concurrent use.
+type Codec[T any] struct {
+	opt  options
+	plan *plan
+}
+
+// NewCodec checks T and creates a codec.
+// If T has types which are not supported, an error with the path is returned.
+func NewCodec[T any](opts ...Option) (*Codec[T], error) {
+	opt := createOptions(opts)
+	p, err := compile(reflect.TypeOf((*T)(nil)).Elem(), opt)
+	if err != nil {
+		return nil, err
+	}
+	return &Codec[T]{opt: opt, plan: p}, nil
+}
+
+// Marshal converts v to byte datas.
+func (c *Codec[T]) Marshal(v T) ([]byte, error) {
+	rv, err := holderValue(reflect.ValueOf(&v).Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	d := createSerializer(c.opt)
+	if !c.plan.isValid() {
+		return d.serializeHolder(rv)
+	}
+	if c.plan.object {
+		return c.plan.serializeStruct(d, rv)
+	}
+	if !c.plan.fixed {
+		return d.serializeHolder(rv)
+	}
+
+	// the size is known
+	d.create = make([]byte, c.plan.size)
+	_, err = d.serialize(rv, 0)
+	return d.create, err
+}
+
+// Unmarshal analyzes byte data and returns it as T.
+func (c *Codec[T]) Unmarshal(data []byte) (T, error) {
+	var v T
+	err := c.UnmarshalTo(data, &v)
+	return v, err
+}
+
+// UnmarshalTo analyzes byte data and sets into v.
+func (c *Codec[T]) UnmarshalTo(data []byte, v *T) error {
+	if v == nil {
+		return fmt.Errorf("holder must not be nil")
+	}
+	rv := allocHolder(reflect.ValueOf(v).Elem())
+	if c.plan.isFixed() && uint32(len(data)) != c.plan.size {
+		return fmt.Errorf("data size is wrong [ %d : %d ]", c.plan.size, len(data))
+	}
+	return createDeserializer(data, c.opt).deserializeHolder(rv)
+}
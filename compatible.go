@@ -0,0 +1,123 @@
+package zeroformatter
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Compatible reports breaking changes from old to cur, with the same rules as serializing.
+// Both of byte data of old read by cur, and byte data of cur read by old are checked.
+//
+// Field names are not used, fields are compared by the index in the object header.
+// Types which have the same wire format are compatible, such as int and int32, or Enum and its underlying type.
+// Raw is compatible with any type.
+// If no breaking change is found, nil is returned. Otherwise, the error is *CheckError.
+func Compatible(old, cur reflect.Type, opts ...Option) error {
+	ol, err := Describe(old, opts...)
+	if err != nil {
+		return err
+	}
+	nl, err := Describe(cur, opts...)
+	if err != nil {
+		return err
+	}
+
+	c := &compatChecker{}
+	path := ol.GoType
+	if ol.ZFType == "Object" && nl.ZFType == "Object" {
+		c.compareObject(ol, nl, path)
+	} else {
+		c.compare(ol, nl, path)
+	}
+
+	if len(c.problems) > 0 {
+		return &CheckError{Problems: c.problems}
+	}
+	return nil
+}
+
+type compatChecker struct {
+	problems []Problem
+}
+
+func (c *compatChecker) compareObject(ol, nl *Layout, path string) {
+	for i := 0; i < len(ol.Fields) && i < len(nl.Fields); i++ {
+		c.compare(ol.Fields[i], nl.Fields[i], path+"."+ol.Fields[i].Name)
+	}
+
+	// checkHeader allows the index which is larger than fields only when the reader has Unknown
+	for i := len(nl.Fields); i < len(ol.Fields); i++ {
+		p := path + "." + ol.Fields[i].Name
+		c.add(p, fmt.Sprintf("index %d is removed, new data can not be read by old", i))
+		if !nl.Unknown {
+			c.add(p, fmt.Sprintf("index %d is removed, old data can not be read by new without Unknown", i))
+		}
+	}
+	for i := len(ol.Fields); i < len(nl.Fields); i++ {
+		p := path + "." + nl.Fields[i].Name
+		c.add(p, fmt.Sprintf("index %d is added, old data can not be read by new", i))
+		if !ol.Unknown {
+			c.add(p, fmt.Sprintf("index %d is added, new data can not be read by old without Unknown", i))
+		}
+	}
+}
+
+func (c *compatChecker) compare(ol, nl *Layout, path string) {
+	// Raw is as it is
+	if ol.ZFType == "Raw" || nl.ZFType == "Raw" {
+		return
+	}
+
+	// enum is serialized as the underlying type
+	if ol.ZFType == "Enum" {
+		ol = ol.Elem
+	}
+	if nl.ZFType == "Enum" {
+		nl = nl.Elem
+	}
+
+	if ol.ZFType != nl.ZFType {
+		c.add(path, fmt.Sprintf("type is changed [ %s(%s) : %s(%s) ]", ol.GoType, ol.ZFType, nl.GoType, nl.ZFType))
+		return
+	}
+	if ol.Fixed != nl.Fixed || ol.Size != nl.Size {
+		c.add(path, fmt.Sprintf("size is changed [ %s : %s ]", sizeString(ol), sizeString(nl)))
+		return
+	}
+
+	switch ol.ZFType {
+	case "FixedSizeList", "VariableSizeList":
+		c.compare(ol.Elem, nl.Elem, path+"[]")
+
+	case "Dictionary":
+		c.compare(ol.Key, nl.Key, path+"[key]")
+		c.compare(ol.Elem, nl.Elem, path+"[value]")
+
+	case "Struct":
+		// already compared in the parent
+		if ol.Recursive || nl.Recursive {
+			if ol.Recursive != nl.Recursive {
+				c.add(path, "recursive struct is changed")
+			}
+			return
+		}
+		if len(ol.Fields) != len(nl.Fields) {
+			c.add(path, fmt.Sprintf("number of struct fields is changed [ %d : %d ]", len(ol.Fields), len(nl.Fields)))
+			return
+		}
+		for i := range ol.Fields {
+			c.compare(ol.Fields[i], nl.Fields[i], path+"."+ol.Fields[i].Name)
+		}
+	}
+}
+
+func (c *compatChecker) add(path string, reason string) {
+	c.problems = append(c.problems, Problem{Path: path, Reason: reason})
+}
+
+func sizeString(l *Layout) string {
+	if l.Fixed {
+		return fmt.Sprintf("fixed(%d)", l.Size)
+	}
+	return "variable"
+}
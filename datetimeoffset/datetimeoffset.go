@@ -2,19 +2,70 @@ package datetimeoffset
 
 import "time"
 
+// DateTimeOffset is a time with the UTC offset it was created in.
+// It corresponds to C# DateTimeOffset, so the location of the embedded time is
+// always a fixed zone which holds the offset only.
 type DateTimeOffset struct {
 	time.Time
 }
 
+// FromTime returns t as DateTimeOffset. The offset of t at that instant is kept.
+// As C# DateTimeOffset, the offset is truncated to whole minutes.
+func FromTime(t time.Time) DateTimeOffset {
+	_, offSec := t.Zone()
+	return DateTimeOffset{
+		t.Round(0).In(fixedZone(offSec / 60 * 60)),
+	}
+}
+
+// Unix returns the DateTimeOffset corresponding to the given Unix time in the local offset.
 func Unix(sec int64, nsec int64) DateTimeOffset {
+	return FromTime(time.Unix(sec, nsec))
+}
+
+// UnixOffset returns the DateTimeOffset corresponding to the given Unix time
+// with offsetMinutes from UTC. Negative offsets are west of UTC.
+func UnixOffset(sec int64, nsec int64, offsetMinutes int) DateTimeOffset {
 	return DateTimeOffset{
-		time.Unix(sec, nsec),
+		time.Unix(sec, nsec).In(fixedZone(offsetMinutes * 60)),
 	}
 }
 
+// Now returns the current time in the local offset.
 func Now() DateTimeOffset {
-	n := time.Now()
-	return DateTimeOffset{
-		time.Unix(n.Unix(), int64(n.Nanosecond())),
+	return FromTime(time.Now())
+}
+
+// In returns d at the same instant with the offset which loc has at that instant.
+func (d DateTimeOffset) In(loc *time.Location) DateTimeOffset {
+	return FromTime(d.Time.In(loc))
+}
+
+// UTC returns d with zero offset.
+func (d DateTimeOffset) UTC() DateTimeOffset {
+	return d.In(time.UTC)
+}
+
+// Local returns d with the local offset.
+func (d DateTimeOffset) Local() DateTimeOffset {
+	return d.In(time.Local)
+}
+
+// Offset returns the offset from UTC.
+func (d DateTimeOffset) Offset() time.Duration {
+	_, offSec := d.Zone()
+	return time.Duration(offSec) * time.Second
+}
+
+// OffsetMinutes returns the offset from UTC in minutes, which is the unit of C# DateTimeOffset.
+func (d DateTimeOffset) OffsetMinutes() int {
+	_, offSec := d.Zone()
+	return offSec / 60
+}
+
+func fixedZone(offSec int) *time.Location {
+	if offSec == 0 {
+		return time.UTC
 	}
+	return time.FixedZone("", offSec)
 }
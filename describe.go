@@ -0,0 +1,243 @@
+package zeroformatter
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Layout is the wire layout of a type, which is computed by the same rules as serializing.
+type Layout struct {
+	// Index is the index in the object header, -1 if it is not a field of Object.
+	Index int
+	// Name is the field name, empty if it is not a field.
+	Name string
+	// GoType is the type in golang.
+	GoType string
+	// ZFType is the type name in ZeroFormatter, such as Int32, Char, DateTimeOffset,
+	// FixedSizeList, VariableSizeList, Dictionary, Object and Struct.
+	ZFType string
+
+	// Fixed is true when the size is always same, then Size is the size of byte data.
+	Fixed bool
+	Size  uint32
+
+	// Fields are the fields of Object and Struct.
+	Fields []*Layout
+	// Key is the key of Dictionary, and Elem is the value of Dictionary,
+	// the element of lists, or the underlying type of Enum.
+	Key  *Layout
+	Elem *Layout
+
+	// Unknown is true when Object keeps fields of undefined indexes.
+	Unknown bool
+	// Recursive is true when the struct is described in the parent, then Fields are omitted.
+	Recursive bool
+}
+
+// Describe returns the wire layout of t. Options which change the layout, such as WithInt64, are used.
+// It returns an error if t has unsupported types.
+func Describe(t reflect.Type, opts ...Option) (*Layout, error) {
+	if t == nil {
+		return nil, fmt.Errorf("type is nil")
+	}
+	for _, p := range checkType(t) {
+		if !p.NilRisk {
+			return nil, p
+		}
+	}
+
+	// as same as Serialize, pointer is dereferenced
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	d := &describer{opt: createOptions(opts), visiting: map[reflect.Type]bool{}}
+	if isInlineStruct(t) {
+		return d.describeObject(t), nil
+	}
+	return d.describe(t, -1, ""), nil
+}
+
+type describer struct {
+	opt      options
+	visiting map[reflect.Type]bool
+}
+
+func (d *describer) describeObject(t reflect.Type) *Layout {
+	l := &Layout{Index: -1, GoType: t.String(), ZFType: "Object", Unknown: hasUnknown(t)}
+	d.visiting[t] = true
+	defer delete(d.visiting, t)
+
+	l.Fixed = !l.Unknown
+	l.Size = uint32(2+numIndex(t)) * byte4
+	for i := 0; i < numIndex(t); i++ {
+		f := d.describe(t.Field(i).Type, i, t.Field(i).Name)
+		l.Fields = append(l.Fields, f)
+		l.Fixed = l.Fixed && f.Fixed
+		l.Size += f.Size
+	}
+	if !l.Fixed {
+		l.Size = 0
+	}
+	return l
+}
+
+func (d *describer) describe(t reflect.Type, index int, name string) *Layout {
+	// pointer is serialized as the element
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	l := &Layout{Index: index, Name: name, GoType: t.String()}
+	l.Size, l.Fixed = d.opt.fixedSize(t)
+
+	if e, ok := findEnum(t); ok {
+		l.ZFType = "Enum"
+		l.Elem = d.describe(e.wire.Type(), -1, "")
+		return l
+	}
+
+	switch t.Kind() {
+	case reflect.Int8:
+		l.ZFType = "SByte"
+	case reflect.Int16:
+		l.ZFType = "Int16"
+	case reflect.Int32:
+		if t == charType {
+			l.ZFType = "Char"
+		} else {
+			l.ZFType = "Int32"
+		}
+	case reflect.Int:
+		if d.opt.int64 {
+			l.ZFType = "Int64"
+		} else {
+			l.ZFType = "Int32"
+		}
+	case reflect.Int64:
+		if t == durationType {
+			l.ZFType = "TimeSpan"
+		} else {
+			l.ZFType = "Int64"
+		}
+	case reflect.Uint8:
+		l.ZFType = "Byte"
+	case reflect.Uint16:
+		l.ZFType = "UInt16"
+	case reflect.Uint32:
+		l.ZFType = "UInt32"
+	case reflect.Uint:
+		if d.opt.int64 {
+			l.ZFType = "UInt64"
+		} else {
+			l.ZFType = "UInt32"
+		}
+	case reflect.Uint64:
+		l.ZFType = "UInt64"
+	case reflect.Float32:
+		l.ZFType = "Single"
+	case reflect.Float64:
+		l.ZFType = "Double"
+	case reflect.Bool:
+		l.ZFType = "Boolean"
+	case reflect.String:
+		l.ZFType = "String"
+
+	case reflect.Array, reflect.Slice:
+		if t == rawType {
+			l.ZFType = "Raw"
+			break
+		}
+		l.Elem = d.describe(t.Elem(), -1, "")
+		if l.Elem.Fixed {
+			l.ZFType = "FixedSizeList"
+		} else {
+			l.ZFType = "VariableSizeList"
+		}
+
+	case reflect.Map:
+		l.ZFType = "Dictionary"
+		l.Key = d.describe(t.Key(), -1, "")
+		l.Elem = d.describe(t.Elem(), -1, "")
+
+	case reflect.Struct:
+		if t == dateTimeType {
+			l.ZFType = "DateTime"
+			break
+		} else if t == dateTimeOffsetType {
+			l.ZFType = "DateTimeOffset"
+			break
+		}
+
+		// nested struct is serialized inline
+		l.ZFType = "Struct"
+		if d.visiting[t] {
+			l.Recursive = true
+			break
+		}
+		d.visiting[t] = true
+		defer delete(d.visiting, t)
+
+		// NOTE : struct is variable, as same as isFixedSize
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).Type == unknownType {
+				continue
+			}
+			l.Fields = append(l.Fields, d.describe(t.Field(i).Type, -1, t.Field(i).Name))
+		}
+	}
+	return l
+}
+
+// String returns the layout as indented lines, one type per line.
+//
+//	main.Struct Object variable
+//	  [0] Int int Int32 fixed(4)
+//	  [1] List []string VariableSizeList variable
+//	    string String variable
+func (l *Layout) String() string {
+	sb := &strings.Builder{}
+	l.write(sb, 0, "")
+	return sb.String()
+}
+
+func (l *Layout) write(sb *strings.Builder, depth int, prefix string) {
+	sb.WriteString(strings.Repeat("  ", depth))
+	if l.Index >= 0 {
+		fmt.Fprintf(sb, "[%d] ", l.Index)
+	}
+	if prefix != "" {
+		sb.WriteString(prefix + " ")
+	}
+	if l.Name != "" {
+		sb.WriteString(l.Name + " ")
+	}
+	sb.WriteString(l.GoType + " " + l.ZFType)
+	if l.Fixed {
+		fmt.Fprintf(sb, " fixed(%d)", l.Size)
+	} else {
+		sb.WriteString(" variable")
+	}
+	if l.Unknown {
+		sb.WriteString(" +unknown")
+	}
+	if l.Recursive {
+		sb.WriteString(" recursive")
+	}
+	sb.WriteString("\n")
+
+	for _, f := range l.Fields {
+		f.write(sb, depth+1, "")
+	}
+	if l.Key != nil {
+		l.Key.write(sb, depth+1, "key")
+	}
+	if l.Elem != nil {
+		if l.Key != nil {
+			l.Elem.write(sb, depth+1, "value")
+		} else {
+			l.Elem.write(sb, depth+1, "")
+		}
+	}
+}
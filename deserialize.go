@@ -7,7 +7,6 @@ import (
 	"math"
 	"reflect"
 	"time"
-	"unicode/utf16"
 	"unsafe"
 
 	"github.com/shamaton/zeroformatter/char"
@@ -16,19 +15,21 @@ import (
 
 type deserializer struct {
 	data []byte
+	opt  options
 }
 
 const minStructDataSize = 9
 
-func createDeserializer(data []byte) *deserializer {
+func createDeserializer(data []byte, opt options) *deserializer {
 	return &deserializer{
 		data: data,
+		opt:  opt,
 	}
 }
 
 // Deserialize analyzes byte data and set into holder.
-func Deserialize(holder interface{}, data []byte) error {
-	ds := createDeserializer(data)
+func Deserialize(holder interface{}, data []byte, opts ...Option) error {
+	ds := createDeserializer(data, createOptions(opts))
 
 	t := reflect.ValueOf(holder)
 	if t.Kind() != reflect.Ptr {
@@ -39,21 +40,50 @@ func Deserialize(holder interface{}, data []byte) error {
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
+	return ds.deserializeHolder(t)
+}
 
+// deserializeHolder sets byte data into t, which holder points to.
+func (d *deserializer) deserializeHolder(t reflect.Value) error {
 	// byte to Struct
 	if t.Kind() == reflect.Struct && !isDateTime(t) && !isDateTimeOffset(t) {
-		return ds.deserializeStruct(t)
+		return d.deserializeStruct(t)
+	}
+
+	// byte as it is
+	if t.Type() == rawType {
+		t.SetBytes(append([]byte{}, d.data...))
+		return nil
 	}
 
 	// byte to primitive
-	_, err := ds.deserialize(t, 0)
+	o, err := d.deserialize(t, 0)
+	if err == nil && d.opt.strict && o != uint32(len(d.data)) {
+		return fmt.Errorf("%d bytes remain after the value", uint32(len(d.data))-o)
+	}
 	return err
 }
 
 func (d *deserializer) deserializeStruct(t reflect.Value) error {
+	lastIndex, err := d.checkHeader(t.Type())
+	if err != nil {
+		return err
+	}
+
+	numField := numIndex(t.Type())
+	for i := 0; i < numField; i++ {
+		if err := d.deserializeAt(t.Field(i), i); err != nil {
+			return err
+		}
+	}
+	return d.readUnknown(t, numField, lastIndex)
+}
+
+// readHeader checks size of the object header and returns the last index.
+func (d *deserializer) readHeader() (uint32, error) {
 	dataLen := len(d.data)
 	if dataLen < minStructDataSize {
-		return fmt.Errorf("data size is not enough: %d", dataLen)
+		return 0, fmt.Errorf("data size is not enough: %d", dataLen)
 	}
 
 	// data lookup
@@ -63,27 +93,33 @@ func (d *deserializer) deserializeStruct(t reflect.Value) error {
 	b, offset := d.readSize4(offset)
 	size := binary.LittleEndian.Uint32(b)
 	if size != uint32(dataLen) {
-		return fmt.Errorf("data size is wrong [ %d : %d ]", size, dataLen)
+		return 0, fmt.Errorf("data size is wrong [ %d : %d ]", size, dataLen)
 	}
 
 	// index
 	b, offset = d.readSize4(offset)
 	dataIndex := binary.LittleEndian.Uint32(b)
-	numField := t.NumField()
-	if dataIndex != uint32(numField-1) {
-		return fmt.Errorf("data index is diffrent [ %d : %d ]", dataIndex, numField-1)
-	}
 
-	for i := 0; i < numField; i++ {
-		b, offset = d.readSize4(offset)
-		dataOffset := binary.LittleEndian.Uint32(b)
-		if _, err := d.deserialize(t.Field(i), dataOffset); err != nil {
-			return err
-		}
+	// offsets
+	if uint64(dataLen) < uint64(3+uint64(dataIndex))*uint64(byte4) {
+		return 0, fmt.Errorf("data size is not enough for header: %d", dataLen)
 	}
-	return nil
+	return dataIndex, nil
 }
 
+// fieldOffset returns the offset of the field in the object header.
+func (d *deserializer) fieldOffset(index int) uint32 {
+	b, _ := d.readSize4(uint32(2+index) * byte4)
+	return binary.LittleEndian.Uint32(b)
+}
+
+var (
+	dateTimeType       = reflect.TypeOf(time.Time{})
+	dateTimeOffsetType = reflect.TypeOf(datetimeoffset.DateTimeOffset{})
+	durationType       = reflect.TypeOf(time.Duration(0))
+	charType           = reflect.TypeOf(char.Char(0))
+)
+
 func isDateTime(value reflect.Value) bool {
 	i := value.Interface()
 	switch i.(type) {
@@ -124,6 +160,10 @@ func isChar(value reflect.Value) bool {
 func (d *deserializer) deserialize(rv reflect.Value, offset uint32) (uint32, error) {
 	var err error
 
+	if e, ok := findEnum(rv.Type()); ok {
+		return d.deserializeEnum(rv, e, offset)
+	}
+
 	switch rv.Kind() {
 	case reflect.Int8:
 		b, o := d.readSize1(offset)
@@ -142,11 +182,10 @@ func (d *deserializer) deserialize(rv reflect.Value, offset uint32) (uint32, err
 	case reflect.Int32:
 		// char is used instead of rune
 		if isChar(rv) {
-			// rune [ushort(2)]
+			// char [ushort(2)]
+			// NOTE : a surrogate is kept as it is, because it can be a part of char[]
 			b, o := d.readSize2(offset)
-			u16s := []uint16{binary.LittleEndian.Uint16(b)}
-			_v := utf16.Decode(u16s)
-			v := char.Char(_v[0])
+			v := char.Char(binary.LittleEndian.Uint16(b))
 			rv.Set(reflect.ValueOf(v))
 
 			// update
@@ -162,13 +201,22 @@ func (d *deserializer) deserialize(rv reflect.Value, offset uint32) (uint32, err
 		}
 
 	case reflect.Int:
-		// Int32 [int(4)]
-		b, o := d.readSize4(offset)
-		_v := binary.LittleEndian.Uint32(b)
-		// NOTE : double cast
-		rv.SetInt(int64(int32(_v)))
-		// update
-		offset = o
+		if d.opt.int64 {
+			// Int64 [long(8)]
+			b, o := d.readSize8(offset)
+			v := binary.LittleEndian.Uint64(b)
+			rv.SetInt(int64(v))
+			// update
+			offset = o
+		} else {
+			// Int32 [int(4)]
+			b, o := d.readSize4(offset)
+			_v := binary.LittleEndian.Uint32(b)
+			// NOTE : double cast
+			rv.SetInt(int64(int32(_v)))
+			// update
+			offset = o
+		}
 
 	case reflect.Int64:
 		if isDuration(rv) {
@@ -176,7 +224,8 @@ func (d *deserializer) deserialize(rv reflect.Value, offset uint32) (uint32, err
 			b, o1 := d.readSize8(offset)
 			seconds := binary.LittleEndian.Uint64(b)
 			b, o2 := d.readSize4(o1)
-			nanos := binary.LittleEndian.Uint32(b)
+			// NOTE : nanos is negative for negative durations
+			nanos := int32(binary.LittleEndian.Uint32(b))
 			v := time.Duration(int64(seconds)*1000*1000 + int64(nanos))
 
 			rv.Set(reflect.ValueOf(v))
@@ -214,11 +263,19 @@ func (d *deserializer) deserialize(rv reflect.Value, offset uint32) (uint32, err
 		offset = o
 
 	case reflect.Uint:
-		b, o := d.readSize4(offset)
-		v := binary.LittleEndian.Uint32(b)
-		rv.SetUint(uint64(v))
-		// update
-		offset = o
+		if d.opt.int64 {
+			b, o := d.readSize8(offset)
+			v := binary.LittleEndian.Uint64(b)
+			rv.SetUint(v)
+			// update
+			offset = o
+		} else {
+			b, o := d.readSize4(offset)
+			v := binary.LittleEndian.Uint32(b)
+			rv.SetUint(uint64(v))
+			// update
+			offset = o
+		}
 
 	case reflect.Uint64:
 		b, o := d.readSize8(offset)
@@ -251,6 +308,8 @@ func (d *deserializer) deserialize(rv reflect.Value, offset uint32) (uint32, err
 			rv.SetBool(true)
 		} else if b == 0x00 {
 			rv.SetBool(false)
+		} else if d.opt.strict {
+			return 0, fmt.Errorf("bool value is not 0 or 1 at %d : %d", offset, b)
 		}
 		// update
 		offset = o
@@ -258,6 +317,9 @@ func (d *deserializer) deserialize(rv reflect.Value, offset uint32) (uint32, err
 	case reflect.String:
 		b, o := d.readSize4(offset)
 		l := binary.LittleEndian.Uint32(b)
+		if uint64(o)+uint64(l) > uint64(len(d.data)) {
+			return 0, fmt.Errorf("string length is wrong at %d : %d", offset, l)
+		}
 		dd := d.data[o : o+l]
 		v := *(*string)(unsafe.Pointer(&dd))
 		rv.SetString(v)
@@ -271,9 +333,10 @@ func (d *deserializer) deserialize(rv reflect.Value, offset uint32) (uint32, err
 			b, o2 := d.readSize4(o1)
 			nanos := binary.LittleEndian.Uint32(b)
 			b, o3 := d.readSize2(o2)
-			offMin := binary.LittleEndian.Uint16(b)
+			// NOTE : offset is signed, west of UTC is negative
+			offMin := int64(int16(binary.LittleEndian.Uint16(b)))
 
-			v := datetimeoffset.Unix(int64(seconds)-int64(offMin*60), int64(nanos))
+			v := datetimeoffset.UnixOffset(int64(seconds)-offMin*60, int64(nanos), int(offMin))
 			rv.Set(reflect.ValueOf(v))
 			// update
 			offset = o3
@@ -283,11 +346,13 @@ func (d *deserializer) deserialize(rv reflect.Value, offset uint32) (uint32, err
 			seconds := binary.LittleEndian.Uint64(b)
 			b, o2 := d.readSize4(o1)
 			nanos := binary.LittleEndian.Uint32(b)
-			v := time.Unix(int64(seconds), int64(nanos))
+			v := time.Unix(int64(seconds), int64(nanos)).In(d.opt.location)
 
 			rv.Set(reflect.ValueOf(v))
 			// update
 			offset = o2
+		} else if rv.Type() == unknownType {
+			// only top level struct has
 		} else {
 			for i := 0; i < rv.NumField(); i++ {
 				offset, err = d.deserialize(rv.Field(i), offset)
@@ -298,6 +363,9 @@ func (d *deserializer) deserialize(rv reflect.Value, offset uint32) (uint32, err
 		}
 
 	case reflect.Slice:
+		if rv.Type() == rawType {
+			return 0, fmt.Errorf("Raw is supported as a field of the top level struct only")
+		}
 
 		// length
 		b, o := d.readSize4(offset)
@@ -307,6 +375,9 @@ func (d *deserializer) deserialize(rv reflect.Value, offset uint32) (uint32, err
 		if l < 0 {
 			return o, nil
 		}
+		if s, ok := d.opt.fixedSize(rv.Type().Elem()); ok && uint64(o)+uint64(s)*uint64(l) > uint64(len(d.data)) {
+			return 0, fmt.Errorf("list length is wrong at %d : %d", offset, l)
+		}
 
 		tmpSlice := reflect.MakeSlice(rv.Type(), l, l)
 
@@ -375,9 +446,11 @@ func (d *deserializer) deserialize(rv reflect.Value, offset uint32) (uint32, err
 			}
 
 			rv.SetMapIndex(k, v)
-			offset = o
 		}
 
+		// update
+		offset = o
+
 	case reflect.Ptr:
 		e := rv.Type().Elem()
 		v := reflect.New(e).Elem()
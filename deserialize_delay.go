@@ -1,30 +1,48 @@
 package zeroformatter
 
 import (
-	"encoding/binary"
 	"fmt"
 	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 )
 
-type delayDeserializer struct {
+// DelayedDecoder deserializes fields of holder on demand.
+// It is created by DelayDeserialize.
+//
+// Fields of nested structs can be deserialized by element or path too,
+// then sibling fields are skipped without deserializing.
+//
+// DelayedDecoder is safe for concurrent use, and each field is deserialized exactly once.
+// But Reset and Reserialize must not be called concurrently with other methods.
+type DelayedDecoder struct {
 	*deserializer
-	holder       reflect.Value
-	processedMap map[uintptr]int
-	indexArray   []uintptr
+	holder   reflect.Value
+	indexMap map[uintptr]int
+	fields   []delayedField
 }
 
-func createDelayDeserialize(deserializer *deserializer, holder reflect.Value, num int) *delayDeserializer {
-	return &delayDeserializer{
+type delayedField struct {
+	mu   sync.Mutex
+	done uint32
+
+	// paths of nested fields which are deserialized
+	nested map[string]struct{}
+}
+
+func createDelayedDecoder(deserializer *deserializer, holder reflect.Value, num int) *DelayedDecoder {
+	return &DelayedDecoder{
 		deserializer: deserializer,
 		holder:       holder,
-		processedMap: map[uintptr]int{},
-		indexArray:   make([]uintptr, num),
+		indexMap:     map[uintptr]int{},
+		fields:       make([]delayedField, num),
 	}
 }
 
 // DelayDeserialize can delay execution processes which analayze byte data and set into holder.
 // If you do not want to deserialize at once, please use this.
-func DelayDeserialize(holder interface{}, data []byte) (*delayDeserializer, error) {
+func DelayDeserialize(holder interface{}, data []byte, opts ...Option) (*DelayedDecoder, error) {
 
 	t := reflect.ValueOf(holder)
 	if t.Kind() != reflect.Ptr {
@@ -41,47 +59,78 @@ func DelayDeserialize(holder interface{}, data []byte) (*delayDeserializer, erro
 		return nil, fmt.Errorf("only defined struct can delay deserialize: %t", holder)
 	}
 
-	// check before detail checking
-	dataLen := len(data)
-	if dataLen < minStructDataSize {
-		return nil, fmt.Errorf("data size is not enough: %d", dataLen)
-	}
-
 	// create deserializer
-	ds := createDeserializer(data)
+	ds := createDeserializer(data, createOptions(opts))
 
-	// check size
-	offset := uint32(0)
-	b, offset := ds.readSize4(offset)
-
-	size := binary.LittleEndian.Uint32(b)
-	if size != uint32(dataLen) {
-		return nil, fmt.Errorf("data size is wrong [ %d : %d ]", size, dataLen)
+	// check size and index
+	lastIndex, err := ds.checkHeader(t.Type())
+	if err != nil {
+		return nil, err
 	}
-
-	// check index
-	b, offset = ds.readSize4(offset)
-	dataIndex := binary.LittleEndian.Uint32(b)
-	numField := t.NumField()
-	if dataIndex != uint32(numField-1) {
-		return nil, fmt.Errorf("data index is diffrent [ %d : %d ]", dataIndex, numField-1)
+	numField := numIndex(t.Type())
+	if err := ds.readUnknown(t, numField, lastIndex); err != nil {
+		return nil, err
 	}
 
-	// create delay deserializer
-	dds := createDelayDeserialize(ds, t, numField)
+	// create delayed decoder
+	dds := createDelayedDecoder(ds, t, numField)
 
 	// make access info
 	for i := 0; i < numField; i++ {
 		e := t.Field(i)
 		p := e.Addr().Pointer()
-		dds.processedMap[p] = i
-		dds.indexArray[i] = p
+		dds.indexMap[p] = i
 	}
 
 	return dds, nil
 }
 
-func (d *delayDeserializer) DeserializeByIndex(i int, indexes ...int) error {
+// Reset sets zero value into holder and makes all fields not deserialized,
+// then data is used for deserializing after this.
+func (d *DelayedDecoder) Reset(data []byte) error {
+	ds := createDeserializer(data, d.opt)
+	lastIndex, err := ds.checkHeader(d.holder.Type())
+	if err != nil {
+		return err
+	}
+
+	d.deserializer = ds
+	d.holder.Set(reflect.Zero(d.holder.Type()))
+	if err := ds.readUnknown(d.holder, len(d.fields), lastIndex); err != nil {
+		return err
+	}
+	for i := range d.fields {
+		atomic.StoreUint32(&d.fields[i].done, 0)
+		d.fields[i].nested = nil
+	}
+	return nil
+}
+
+// DeserializeAll deserializes all fields which are not deserialized yet.
+func (d *DelayedDecoder) DeserializeAll() error {
+	for i := range d.fields {
+		if err := d.deserializeByIndex(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remaining returns the number of fields which are not deserialized yet.
+// Fields of nested structs are not counted.
+func (d *DelayedDecoder) Remaining() int {
+	n := 0
+	for i := range d.fields {
+		if !d.isDeserialized(i) {
+			n++
+		}
+	}
+	return n
+}
+
+// DeserializeByIndex deserializes the fields at the indexes.
+// Fields which are already deserialized are skipped.
+func (d *DelayedDecoder) DeserializeByIndex(i int, indexes ...int) error {
 	// index
 	if err := d.deserializeByIndex(i); err != nil {
 		return err
@@ -96,16 +145,17 @@ func (d *delayDeserializer) DeserializeByIndex(i int, indexes ...int) error {
 	return nil
 }
 
-func (d *delayDeserializer) deserializeByIndex(i int) error {
-	if i >= len(d.indexArray) {
+func (d *DelayedDecoder) deserializeByIndex(i int) error {
+	if i < 0 || i >= len(d.fields) {
 		return fmt.Errorf("this index is out of range : %d", i)
 	}
-
-	addr := d.indexArray[i]
-	return d.deserializeByAddress(addr)
+	return d.deserializeField(i, d.holder.Type().Field(i).Name)
 }
 
-func (d *delayDeserializer) DeserializeByElement(element interface{}, elements ...interface{}) error {
+// DeserializeByElement deserializes the fields which elements point to, such as &holder.Field.
+// Elements can point fields of nested structs, such as &holder.Child.Field.
+// Fields which are already deserialized are skipped.
+func (d *DelayedDecoder) DeserializeByElement(element interface{}, elements ...interface{}) error {
 	// element
 	if err := d.deserializeByElement(element); err != nil {
 		return err
@@ -120,66 +170,340 @@ func (d *delayDeserializer) DeserializeByElement(element interface{}, elements .
 	return nil
 }
 
-func (d *delayDeserializer) deserializeByElement(element interface{}) error {
+func (d *DelayedDecoder) deserializeByElement(element interface{}) error {
+	index, path, err := d.findElement(element)
+	if err != nil {
+		return err
+	}
+	return d.deserializeField(index, path)
+}
 
-	t := reflect.ValueOf(element)
-	if t.Kind() != reflect.Ptr {
-		return fmt.Errorf("element must set pointer value. but got: %t", element)
+// DeserializeByPath deserializes the fields which paths point to, such as "Child.Field".
+// Fields which are already deserialized are skipped.
+func (d *DelayedDecoder) DeserializeByPath(path string, paths ...string) error {
+	// path
+	if err := d.deserializeByPath(path); err != nil {
+		return err
 	}
 
-	t = t.Elem()
-	if t.Kind() == reflect.Ptr {
-		t = t.Elem()
+	// paths
+	for _, p := range paths {
+		if err := d.deserializeByPath(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *DelayedDecoder) deserializeByPath(path string) error {
+	index, err := d.findPath(path)
+	if err != nil {
+		return err
+	}
+	return d.deserializeField(index, path)
+}
+
+// findElement returns the index of the top level field and the path of element.
+func (d *DelayedDecoder) findElement(element interface{}) (int, string, error) {
+	t := reflect.ValueOf(element)
+	if t.Kind() != reflect.Ptr || t.IsNil() {
+		return 0, "", fmt.Errorf("element must set pointer value. but got: %t", element)
 	}
+	t = t.Elem()
 
 	// address
 	address := t.Addr().Pointer()
-	return d.deserializeByAddress(address)
+	if index, ok := d.indexMap[address]; ok && d.holder.Field(index).Type() == t.Type() {
+		return index, d.holder.Type().Field(index).Name, nil
+	}
+
+	// nested
+	for i := 0; i < d.holder.NumField(); i++ {
+		if path, ok := findNested(d.holder.Field(i), d.holder.Type().Field(i).Name, address, t.Type()); ok {
+			return i, path, nil
+		}
+	}
+	return 0, "", fmt.Errorf("not found address: %v", address)
+}
+
+func findNested(rv reflect.Value, path string, address uintptr, t reflect.Type) (string, bool) {
+	if !isInlineStruct(rv.Type()) {
+		return "", false
+	}
+	base := rv.Addr().Pointer()
+	if address < base || address >= base+rv.Type().Size() {
+		return "", false
+	}
+
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Field(i)
+		p := path + "." + rv.Type().Field(i).Name
+		if f.Addr().Pointer() == address && f.Type() == t {
+			return p, true
+		}
+		if p, ok := findNested(f, p, address, t); ok {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// findPath returns the index of the top level field of path.
+func (d *DelayedDecoder) findPath(path string) (int, error) {
+	names := strings.Split(path, ".")
+	index := -1
+	t := d.holder.Type()
+	for i, name := range names {
+		if i > 0 && !isInlineStruct(t) {
+			return 0, fmt.Errorf("not found path: %s", path)
+		}
+		f, ok := t.FieldByName(name)
+		if !ok || len(f.Index) != 1 {
+			return 0, fmt.Errorf("not found path: %s", path)
+		}
+		if i == 0 {
+			index = f.Index[0]
+		}
+		t = f.Type
+	}
+	return index, nil
+}
+
+func isInlineStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != dateTimeType && t != dateTimeOffsetType
 }
 
-func (d *delayDeserializer) deserializeByAddress(address uintptr) error {
-	index, ok := d.processedMap[address]
-	if !ok {
-		return fmt.Errorf("not found address: %v", address)
+// locate returns the value and the offset of the nested field.
+func (d *DelayedDecoder) locate(index int, path string) (reflect.Value, uint32, error) {
+	rv := d.holder.Field(index)
+	o := d.fieldOffset(index)
+
+	names := strings.Split(path, ".")
+	for _, name := range names[1:] {
+		t := rv.Type()
+		f, _ := t.FieldByName(name)
+		// skip siblings
+		for i := 0; i < f.Index[0]; i++ {
+			var err error
+			o, err = d.skip(t.Field(i).Type, o)
+			if err != nil {
+				return reflect.Value{}, 0, err
+			}
+		}
+		rv = rv.Field(f.Index[0])
 	}
+	return rv, o, nil
+}
 
+func (d *DelayedDecoder) deserializeField(index int, path string) error {
 	// already deserialized
-	if index < 0 {
+	if d.isDeserialized(index) {
 		return nil
 	}
 
-	// value
-	rv := d.holder.Field(index)
-	// offset
-	off := 8 + uint32(index)*byte4
-	b, _ := d.readSize4(off)
-	dataIndex := binary.LittleEndian.Uint32(b)
+	f := &d.fields[index]
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.done != 0 || f.isNestedDone(path) {
+		return nil
+	}
+
+	// value and offset
+	rv, dataIndex, err := d.locate(index, path)
+	if err != nil {
+		return err
+	}
 
 	// deserialize and update flag
-	d.deserialize(rv, dataIndex)
-	d.processedMap[address] = -1
+	if strings.Contains(path, ".") || f.hasNestedUnder(path) {
+		_, err = d.deserializeRest(f, rv, dataIndex, path)
+	} else {
+		err = d.deserializeAt(rv, index)
+	}
+	if err != nil {
+		return err
+	}
+	if strings.Contains(path, ".") {
+		if f.nested == nil {
+			f.nested = map[string]struct{}{}
+		}
+		f.nested[path] = struct{}{}
+	} else {
+		f.nested = nil
+		atomic.StoreUint32(&f.done, 1)
+	}
 	return nil
 }
 
-func (d *delayDeserializer) IsDeserialized(element interface{}) (bool, error) {
+// deserializeRest deserializes the value of path except nested fields which are already deserialized.
+func (d *DelayedDecoder) deserializeRest(f *delayedField, rv reflect.Value, offset uint32, path string) (uint32, error) {
+	if _, ok := f.nested[path]; ok {
+		return d.skip(rv.Type(), offset)
+	}
+	if !f.hasNestedUnder(path) {
+		return d.deserialize(rv, offset)
+	}
 
-	t := reflect.ValueOf(element)
-	if t.Kind() != reflect.Ptr {
-		return false, fmt.Errorf("holder must set pointer value. but got: %t", element)
+	o := offset
+	for i := 0; i < rv.NumField(); i++ {
+		var err error
+		o, err = d.deserializeRest(f, rv.Field(i), o, path+"."+rv.Type().Field(i).Name)
+		if err != nil {
+			return 0, err
+		}
 	}
+	return o, nil
+}
 
-	t = t.Elem()
-	if t.Kind() == reflect.Ptr {
-		t = t.Elem()
+func (d *DelayedDecoder) isDeserialized(index int) bool {
+	return atomic.LoadUint32(&d.fields[index].done) != 0
+}
+
+// isNestedDone reports whether path or its parent is deserialized.
+func (f *delayedField) isNestedDone(path string) bool {
+	for p := path; strings.Contains(p, "."); p = p[:strings.LastIndex(p, ".")] {
+		if _, ok := f.nested[p]; ok {
+			return true
+		}
 	}
+	return false
+}
 
-	// address
-	address := t.Addr().Pointer()
+// hasNestedUnder reports whether some of fields under path are deserialized.
+func (f *delayedField) hasNestedUnder(path string) bool {
+	for p := range f.nested {
+		if strings.HasPrefix(p, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDeserialized reports whether the field which element points to is already deserialized.
+func (d *DelayedDecoder) IsDeserialized(element interface{}) (bool, error) {
+	index, path, err := d.findElement(element)
+	if err != nil {
+		return false, err
+	}
+	if d.isDeserialized(index) {
+		return true, nil
+	}
+
+	f := &d.fields[index]
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.done != 0 || f.isNestedDone(path), nil
+}
+
+// Set sets value into the top level field of name and makes the field deserialized,
+// so that Reserialize serializes value instead of the original data.
+func (d *DelayedDecoder) Set(name string, value interface{}) error {
+	f, ok := d.holder.Type().FieldByName(name)
+	if !ok || len(f.Index) != 1 || f.Index[0] >= len(d.fields) {
+		return fmt.Errorf("not found field: %s", name)
+	}
+	rv := reflect.ValueOf(value)
+	if !rv.IsValid() || !rv.Type().AssignableTo(f.Type) {
+		return fmt.Errorf("value of %T can not be set into %v", value, f.Type)
+	}
+
+	field := &d.fields[f.Index[0]]
+	field.mu.Lock()
+	defer field.mu.Unlock()
+	d.holder.Field(f.Index[0]).Set(rv)
+	field.nested = nil
+	atomic.StoreUint32(&field.done, 1)
+	return nil
+}
+
+// Reserialize converts holder to byte datas again.
+// Fields which are not deserialized yet are copied from the original data as they are,
+// and only deserialized fields are serialized. So please deserialize fields or use Set before changing them.
+// If a field which is not deserialized has non zero value in holder, an error is returned
+// instead of dropping the value.
+func (d *DelayedDecoder) Reserialize() ([]byte, error) {
+	numField := len(d.fields)
+	unknown := unknownOf(d.holder)
+	parts := make([][]byte, numField, numField+len(unknown))
+	size := uint32(2+numField+len(unknown)) * byte4
+
+	for i := 0; i < numField; i++ {
+		if d.isDeserialized(i) {
+			b, err := serializeValue(d.holder.Field(i), d.opt)
+			if err != nil {
+				return nil, err
+			}
+			parts[i] = b
+		} else if d.holder.Field(i).Type() == rawType {
+			if !d.holder.Field(i).IsZero() {
+				return nil, errNotDeserialized(d.holder.Type().Field(i).Name)
+			}
+			start, end, err := d.fieldRegion(i)
+			if err != nil {
+				return nil, err
+			}
+			parts[i] = d.data[start:end]
+		} else {
+			b, _, err := d.reserialize(&d.fields[i], d.holder.Field(i), d.fieldOffset(i), d.holder.Type().Field(i).Name)
+			if err != nil {
+				return nil, err
+			}
+			parts[i] = b
+		}
+		size += uint32(len(parts[i]))
+	}
+	for _, u := range unknown {
+		parts = append(parts, u)
+		size += uint32(len(u))
+	}
+
+	s := createSerializer(d.opt)
+	s.create = make([]byte, size)
+	s.writeSize4Uint32(size, 0)
+	s.writeSize4Int(len(parts)-1, byte4)
+
+	offset := uint32(2+len(parts)) * byte4
+	for i, part := range parts {
+		s.writeSize4Uint32(offset, uint32(2+i)*byte4)
+		copy(s.create[offset:], part)
+		offset += uint32(len(part))
+	}
+	return s.create, nil
+}
+
+// reserialize returns byte datas of path and the offset next to the original value.
+func (d *DelayedDecoder) reserialize(f *delayedField, rv reflect.Value, offset uint32, path string) ([]byte, uint32, error) {
+	end, err := d.skip(rv.Type(), offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if _, ok := f.nested[path]; ok {
+		b, err := serializeValue(rv, d.opt)
+		return b, end, err
+	}
+
+	// untouched region
+	if !f.hasNestedUnder(path) {
+		if !rv.IsZero() {
+			return nil, 0, errNotDeserialized(path)
+		}
+		return d.data[offset:end], end, nil
+	}
 
-	index, ok := d.processedMap[address]
-	if !ok {
-		return false, fmt.Errorf("not found element: %t", element)
+	b := make([]byte, 0, end-offset)
+	o := offset
+	for i := 0; i < rv.NumField(); i++ {
+		var part []byte
+		part, o, err = d.reserialize(f, rv.Field(i), o, path+"."+rv.Type().Field(i).Name)
+		if err != nil {
+			return nil, 0, err
+		}
+		b = append(b, part...)
 	}
+	return b, end, nil
+}
 
-	return (index < 0), nil
+func errNotDeserialized(path string) error {
+	return fmt.Errorf("%s is changed without deserializing, please deserialize it or use Set", path)
 }
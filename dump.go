@@ -0,0 +1,303 @@
+package zeroformatter
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/shamaton/zeroformatter/char"
+)
+
+// dumpMaxString is the max length of strings which are printed in Dump.
+const dumpMaxString = 64
+
+// Dump prints byte data as an annotated tree of offsets, lengths and values.
+// If t is nil, the object header and byte data of each field are printed as hex.
+// If t is not an object, the value is printed without the header.
+//
+// Malformed regions are marked with "!!", and printing is continued from the next field.
+// If malformed regions are found, an error which has the number of them is returned.
+//
+//	00000000  size      : 26
+//	00000004  lastIndex : 1
+//	00000008  offset[0] : 16
+//	0000000c  offset[1] : 20
+//	00000010  [0] ID int32 : 1
+//	00000014  [1] Name string len=2 : "zf"
+func Dump(w io.Writer, data []byte, t reflect.Type, opts ...Option) error {
+	dp := &dumper{
+		w: bufio.NewWriter(w),
+		d: createDeserializer(data, createOptions(opts)),
+	}
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || isInlineStruct(t) {
+		dp.object(t)
+	} else if o, ok := dp.value(t, 0, "", 0); ok && o != uint32(len(data)) {
+		dp.malformed(o, 0, fmt.Sprintf("%d bytes are not read", uint32(len(data))-o))
+		dp.hex(o, uint32(len(data)), 0)
+	}
+
+	if err := dp.w.Flush(); err != nil {
+		return err
+	}
+	if dp.problems > 0 {
+		return fmt.Errorf("%d malformed regions are found", dp.problems)
+	}
+	return nil
+}
+
+type dumper struct {
+	w        *bufio.Writer
+	d        *deserializer
+	problems int
+}
+
+func (dp *dumper) line(offset uint32, depth int, text string) {
+	fmt.Fprintf(dp.w, "%08x  %s%s\n", offset, strings.Repeat("  ", depth), text)
+}
+
+func (dp *dumper) malformed(offset uint32, depth int, reason string) {
+	dp.problems++
+	dp.line(offset, depth, "!! "+reason)
+}
+
+// hex prints byte data from start to end, 16 bytes per line.
+func (dp *dumper) hex(start, end uint32, depth int) {
+	for o := start; o < end; o += 16 {
+		e := o + 16
+		if e > end {
+			e = end
+		}
+		dp.line(o, depth, fmt.Sprintf("% x", dp.d.data[o:e]))
+	}
+}
+
+func (dp *dumper) object(t reflect.Type) {
+	data := dp.d.data
+	dataLen := uint32(len(data))
+	if dataLen < byte4*2 {
+		dp.malformed(0, 0, fmt.Sprintf("data size is not enough for header: %d", dataLen))
+		dp.hex(0, dataLen, 0)
+		return
+	}
+
+	size := binary.LittleEndian.Uint32(data)
+	if size == dataLen {
+		dp.line(0, 0, fmt.Sprintf("size      : %d", size))
+	} else {
+		dp.malformed(0, 0, fmt.Sprintf("size is wrong : %d, data size is %d", size, dataLen))
+	}
+
+	lastIndex := binary.LittleEndian.Uint32(data[byte4:])
+	dp.line(byte4, 0, fmt.Sprintf("lastIndex : %d", lastIndex))
+
+	// offsets which are in data
+	count := uint64(lastIndex) + 1
+	if max := uint64(dataLen/byte4) - 2; count > max {
+		dp.malformed(byte4, 0, fmt.Sprintf("offsets are out of data : %d offsets, %d in data", count, max))
+		count = max
+	}
+	headerEnd := uint32(2+count) * byte4
+	offsets := make([]uint32, count)
+	valid := make([]bool, count)
+	for i := range offsets {
+		o := uint32(2+i) * byte4
+		offsets[i] = binary.LittleEndian.Uint32(data[o:])
+		valid[i] = headerEnd <= offsets[i] && offsets[i] <= dataLen
+		if valid[i] {
+			dp.line(o, 0, fmt.Sprintf("offset[%d] : %d", i, offsets[i]))
+		} else {
+			dp.malformed(o, 0, fmt.Sprintf("offset[%d] is out of data : %d", i, offsets[i]))
+		}
+	}
+
+	numField := 0
+	if t != nil {
+		numField = numIndex(t)
+		if int(lastIndex) != numField-1 && !(hasUnknown(t) && int(lastIndex) >= numField-1) {
+			dp.malformed(byte4, 0, fmt.Sprintf("lastIndex is different from %v : %d fields", t, numField))
+		}
+	}
+
+	for i, start := range offsets {
+		if !valid[i] {
+			continue
+		}
+		// the field ends at the nearest offset behind, as same as fieldRegion
+		end := dataLen
+		for j, o := range offsets {
+			if valid[j] && start < o && o < end {
+				end = o
+			}
+		}
+
+		if i >= numField {
+			label := fmt.Sprintf("[%d]", i)
+			if t != nil {
+				label += " Unknown"
+			}
+			dp.line(start, 0, fmt.Sprintf("%s bytes=%d", label, end-start))
+			dp.hex(start, end, 1)
+			continue
+		}
+
+		f := t.Field(i)
+		if f.Type == rawType {
+			dp.line(start, 0, fmt.Sprintf("[%d] %s Raw bytes=%d", i, fieldName(f), end-start))
+			dp.hex(start, end, 1)
+			continue
+		}
+
+		// restrict the field to the region, then overrun is found as short data
+		dp.d.data = data[:end]
+		o, ok := dp.value(f.Type, start, fmt.Sprintf("[%d] %s ", i, fieldName(f)), 0)
+		dp.d.data = data
+		if ok && o != end {
+			dp.malformed(o, 1, fmt.Sprintf("%d bytes are not read", end-o))
+			dp.hex(o, end, 1)
+		}
+	}
+}
+
+// value prints the value of t at offset, and returns the offset next to it.
+// false is returned if the value is malformed.
+func (dp *dumper) value(t reflect.Type, offset uint32, name string, depth int) (uint32, bool) {
+	label := name + dumpTypeName(t)
+	dataLen := uint64(len(dp.d.data))
+
+	if s, ok := dp.d.opt.fixedSize(t); ok {
+		if uint64(offset)+uint64(s) > dataLen {
+			dp.malformed(offset, depth, fmt.Sprintf("%s : data is short for %d bytes", label, s))
+			return 0, false
+		}
+		if t.Kind() == reflect.Bool && dp.d.data[offset] > 1 {
+			dp.malformed(offset, depth, fmt.Sprintf("%s : bool is not 0 or 1 : %d", label, dp.d.data[offset]))
+			return offset + s, true
+		}
+
+		rv := reflect.New(t).Elem()
+		if _, err := dp.d.deserialize(rv, offset); err != nil {
+			dp.malformed(offset, depth, fmt.Sprintf("%s : %v", label, err))
+			return offset + s, true
+		}
+		if c, ok := rv.Interface().(char.Char); ok {
+			dp.line(offset, depth, fmt.Sprintf("%s : %U", label, rune(c)))
+		} else {
+			dp.line(offset, depth, fmt.Sprintf("%s : %v", label, rv.Interface()))
+		}
+		return offset + s, true
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		l, o, err := dp.d.readLength(offset)
+		if err != nil || l < 0 || uint64(o)+uint64(l) > dataLen {
+			dp.malformed(offset, depth, fmt.Sprintf("%s : string length is wrong : %d", label, l))
+			return 0, false
+		}
+		s := string(dp.d.data[o : o+uint32(l)])
+		if len(s) > dumpMaxString {
+			s = s[:dumpMaxString] + "..."
+		}
+		dp.line(offset, depth, fmt.Sprintf("%s len=%d : %q", label, l, s))
+		return o + uint32(l), true
+
+	case reflect.Array, reflect.Slice:
+		if t == rawType {
+			dp.malformed(offset, depth, fmt.Sprintf("%s : Raw is supported as a field of the top level struct only", label))
+			return 0, false
+		}
+		l, o, err := dp.d.readLength(offset)
+		if err != nil {
+			dp.malformed(offset, depth, fmt.Sprintf("%s : %v", label, err))
+			return 0, false
+		}
+		if l < 0 {
+			dp.line(offset, depth, label+" : null")
+			return o, true
+		}
+		if t.Kind() == reflect.Array && l != t.Len() {
+			dp.malformed(offset, depth, fmt.Sprintf("%s : array length is different : %d", label, l))
+			return 0, false
+		}
+		if s, ok := dp.d.opt.fixedSize(t.Elem()); ok && uint64(o)+uint64(s)*uint64(l) > dataLen {
+			dp.malformed(offset, depth, fmt.Sprintf("%s : list length is wrong : %d", label, l))
+			return 0, false
+		}
+		dp.line(offset, depth, fmt.Sprintf("%s len=%d", label, l))
+		for i := 0; i < l; i++ {
+			var ok bool
+			o, ok = dp.value(t.Elem(), o, fmt.Sprintf("[%d] ", i), depth+1)
+			if !ok {
+				return 0, false
+			}
+		}
+		return o, true
+
+	case reflect.Map:
+		l, o, err := dp.d.readLength(offset)
+		if err != nil || l < 0 {
+			dp.malformed(offset, depth, fmt.Sprintf("%s : map length is wrong : %d", label, l))
+			return 0, false
+		}
+		dp.line(offset, depth, fmt.Sprintf("%s len=%d", label, l))
+		for i := 0; i < l; i++ {
+			var ok bool
+			o, ok = dp.value(t.Key(), o, fmt.Sprintf("[%d] key ", i), depth+1)
+			if !ok {
+				return 0, false
+			}
+			o, ok = dp.value(t.Elem(), o, fmt.Sprintf("[%d] value ", i), depth+1)
+			if !ok {
+				return 0, false
+			}
+		}
+		return o, true
+
+	case reflect.Struct:
+		if t == unknownType {
+			return offset, true
+		}
+		dp.line(offset, depth, label)
+		o := offset
+		for i := 0; i < t.NumField(); i++ {
+			var ok bool
+			o, ok = dp.value(t.Field(i).Type, o, fieldName(t.Field(i))+" ", depth+1)
+			if !ok {
+				return 0, false
+			}
+		}
+		return o, true
+
+	case reflect.Ptr:
+		return dp.value(t.Elem(), offset, name, depth)
+	}
+
+	dp.malformed(offset, depth, fmt.Sprint(label, " : this type is not supported"))
+	return 0, false
+}
+
+// dumpTypeName returns the name of t, which is short for structs without name.
+func dumpTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Slice:
+		return "[]" + dumpTypeName(t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), dumpTypeName(t.Elem()))
+	case reflect.Map:
+		return "map[" + dumpTypeName(t.Key()) + "]" + dumpTypeName(t.Elem())
+	case reflect.Ptr:
+		return "*" + dumpTypeName(t.Elem())
+	case reflect.Struct:
+		if t.Name() == "" {
+			return "struct"
+		}
+	}
+	return t.String()
+}
@@ -0,0 +1,254 @@
+package zeroformatter
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// DynamicCodec serializes and deserializes an object in the schema as map[string]interface{}.
+// Keys of the map are field names in the schema.
+//
+// Values are decoded as golang types of the ZeroFormatter types, such as int32 for Int32,
+// char.Char for Char, datetimeoffset.DateTimeOffset for DateTimeOffset and time.Duration for TimeSpan.
+// List is decoded as []interface{}, Dictionary is decoded as map[string]interface{} if the key is String,
+// otherwise map[interface{}]interface{}, and objects are decoded as map[string]interface{}.
+//
+// In encoding, numbers can be any golang number types if the value fits in the type,
+// missing fields are zero values, and lists and dictionaries can be any slices and maps.
+// DynamicCodec is safe for concurrent use.
+type DynamicCodec struct {
+	name string
+	t    reflect.Type
+	opt  options
+}
+
+// Codec creates a codec of the object which has the name.
+func (s *Schema) Codec(name string, opts ...Option) (*DynamicCodec, error) {
+	t, ok := s.types[name]
+	if !ok {
+		return nil, fmt.Errorf("object is not defined : %s", name)
+	}
+	return &DynamicCodec{name: name, t: t, opt: createOptions(opts)}, nil
+}
+
+// Type returns the golang struct type of the object.
+func (c *DynamicCodec) Type() reflect.Type {
+	return c.t
+}
+
+// Encode converts v to byte datas.
+func (c *DynamicCodec) Encode(v map[string]interface{}) ([]byte, error) {
+	rv := reflect.New(c.t).Elem()
+	if err := fromDynamic(rv, v, c.name); err != nil {
+		return nil, err
+	}
+	return createSerializer(c.opt).serializeHolder(rv)
+}
+
+// Decode analyzes byte data and returns it as map[string]interface{}.
+func (c *DynamicCodec) Decode(data []byte) (map[string]interface{}, error) {
+	rv := reflect.New(c.t).Elem()
+	if err := createDeserializer(data, c.opt).deserializeHolder(rv); err != nil {
+		return nil, err
+	}
+	return toDynamic(rv).(map[string]interface{}), nil
+}
+
+// toDynamic converts the value of the schema type to the dynamic value.
+func toDynamic(rv reflect.Value) interface{} {
+	switch rv.Kind() {
+	case reflect.Struct:
+		if !isInlineStruct(rv.Type()) {
+			break
+		}
+		m := make(map[string]interface{}, rv.NumField())
+		for i := 0; i < rv.NumField(); i++ {
+			m[fieldName(rv.Type().Field(i))] = toDynamic(rv.Field(i))
+		}
+		return m
+
+	case reflect.Slice:
+		l := make([]interface{}, rv.Len())
+		for i := range l {
+			l[i] = toDynamic(rv.Index(i))
+		}
+		return l
+
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String {
+			m := make(map[string]interface{}, rv.Len())
+			for _, k := range rv.MapKeys() {
+				m[k.String()] = toDynamic(rv.MapIndex(k))
+			}
+			return m
+		}
+		m := make(map[interface{}]interface{}, rv.Len())
+		for _, k := range rv.MapKeys() {
+			m[k.Interface()] = toDynamic(rv.MapIndex(k))
+		}
+		return m
+	}
+	return rv.Interface()
+}
+
+// fromDynamic sets the dynamic value v into rv of the schema type.
+func fromDynamic(rv reflect.Value, v interface{}, path string) error {
+	// zero value
+	if v == nil {
+		return nil
+	}
+	sv := reflect.ValueOf(v)
+	t := rv.Type()
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if !isInlineStruct(t) {
+			break
+		}
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s : object must be map[string]interface{}. but got: %T", path, v)
+		}
+		for name, fv := range m {
+			i := schemaFieldIndex(t, name)
+			if i < 0 {
+				return fmt.Errorf("%s : field is not defined : %s", path, name)
+			}
+			if err := fromDynamic(rv.Field(i), fv, path+"."+name); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Slice:
+		if sv.Kind() != reflect.Slice && sv.Kind() != reflect.Array {
+			return fmt.Errorf("%s : List must be slice. but got: %T", path, v)
+		}
+		l := reflect.MakeSlice(t, sv.Len(), sv.Len())
+		for i := 0; i < sv.Len(); i++ {
+			if err := fromDynamic(l.Index(i), sv.Index(i).Interface(), fmt.Sprint(path, "[", i, "]")); err != nil {
+				return err
+			}
+		}
+		rv.Set(l)
+		return nil
+
+	case reflect.Map:
+		if sv.Kind() != reflect.Map {
+			return fmt.Errorf("%s : Dictionary must be map. but got: %T", path, v)
+		}
+		m := reflect.MakeMapWithSize(t, sv.Len())
+		iter := sv.MapRange()
+		for iter.Next() {
+			k := reflect.New(t.Key()).Elem()
+			e := reflect.New(t.Elem()).Elem()
+			p := fmt.Sprint(path, "[", iter.Key().Interface(), "]")
+			if err := fromDynamic(k, iter.Key().Interface(), p); err != nil {
+				return err
+			}
+			if err := fromDynamic(e, iter.Value().Interface(), p); err != nil {
+				return err
+			}
+			m.SetMapIndex(k, e)
+		}
+		rv.Set(m)
+		return nil
+
+	case
+		reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return setNumber(rv, sv, path)
+
+	case reflect.String, reflect.Bool:
+		if sv.Kind() == t.Kind() {
+			rv.Set(sv.Convert(t))
+			return nil
+		}
+	}
+
+	if !sv.Type().AssignableTo(t) {
+		return fmt.Errorf("%s : value must be %v. but got: %T", path, t, v)
+	}
+	rv.Set(sv)
+	return nil
+}
+
+// schemaFieldIndex returns the index of the field which has the name in the schema, -1 if not found.
+func schemaFieldIndex(t reflect.Type, name string) int {
+	for i := 0; i < t.NumField(); i++ {
+		if fieldName(t.Field(i)) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// setNumber sets the number sv into rv, with checking the range of rv.
+func setNumber(rv, sv reflect.Value, path string) error {
+	overflow := false
+	switch sv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := sv.Int()
+		switch {
+		case isSignedKind(rv.Kind()):
+			overflow = rv.OverflowInt(i)
+			if !overflow {
+				rv.SetInt(i)
+			}
+		case isIntegerKind(rv.Kind()):
+			overflow = i < 0 || rv.OverflowUint(uint64(i))
+			if !overflow {
+				rv.SetUint(uint64(i))
+			}
+		default:
+			rv.SetFloat(float64(i))
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u := sv.Uint()
+		switch {
+		case isSignedKind(rv.Kind()):
+			overflow = u > math.MaxInt64 || rv.OverflowInt(int64(u))
+			if !overflow {
+				rv.SetInt(int64(u))
+			}
+		case isIntegerKind(rv.Kind()):
+			overflow = rv.OverflowUint(u)
+			if !overflow {
+				rv.SetUint(u)
+			}
+		default:
+			rv.SetFloat(float64(u))
+		}
+
+	case reflect.Float32, reflect.Float64:
+		f := sv.Float()
+		switch {
+		case isSignedKind(rv.Kind()):
+			overflow = f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 || rv.OverflowInt(int64(f))
+			if !overflow {
+				rv.SetInt(int64(f))
+			}
+		case isIntegerKind(rv.Kind()):
+			overflow = f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 || rv.OverflowUint(uint64(f))
+			if !overflow {
+				rv.SetUint(uint64(f))
+			}
+		default:
+			overflow = rv.OverflowFloat(f) && !math.IsInf(f, 0)
+			if !overflow {
+				rv.SetFloat(f)
+			}
+		}
+
+	default:
+		return fmt.Errorf("%s : value must be number. but got: %v", path, sv.Type())
+	}
+
+	if overflow {
+		return fmt.Errorf("%s : value does not fit in %v : %v", path, rv.Type(), sv.Interface())
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package zeroformatter
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+type enumInfo struct {
+	kind   reflect.Kind
+	size   uint32
+	wire   reflect.Value
+	values map[int64]struct{}
+}
+
+var (
+	enumMutex sync.Mutex
+	enumMap   atomic.Value // map[reflect.Type]*enumInfo
+
+	// enumVersion is counted up by RegisterEnum, so sizes which are computed before can be found out of date.
+	enumVersion uint64
+)
+
+func init() {
+	enumMap.Store(map[reflect.Type]*enumInfo{})
+}
+
+// RegisterEnum sets the underlying type of enum t, which is used in serializing.
+//
+// golang has no enum, so named integer types (type Color int32) are serialized by its kind.
+// This is for matching the size to C# enum, such as enum Color : byte.
+// kind is one of reflect.Int8, Uint8, Int16, Uint16, Int32, Uint32, Int64 and Uint64.
+// If values are set, deserializing a value which is not in values returns an error.
+//
+//	type Color int
+//	zeroformatter.RegisterEnum(reflect.TypeOf(Color(0)), reflect.Int32, Red, Green, Blue)
+func RegisterEnum(t reflect.Type, kind reflect.Kind, values ...interface{}) error {
+	if t == nil || t.Name() == "" || t.PkgPath() == "" || !isIntegerKind(t.Kind()) {
+		return fmt.Errorf("enum must be a named integer type : %v", t)
+	}
+	if t == charType || t == durationType {
+		return fmt.Errorf("this type can not be enum : %v", t)
+	}
+
+	e := &enumInfo{kind: kind}
+	switch kind {
+	case reflect.Int8:
+		e.wire, e.size = reflect.ValueOf(int8(0)), byte1
+	case reflect.Uint8:
+		e.wire, e.size = reflect.ValueOf(uint8(0)), byte1
+	case reflect.Int16:
+		e.wire, e.size = reflect.ValueOf(int16(0)), byte2
+	case reflect.Uint16:
+		e.wire, e.size = reflect.ValueOf(uint16(0)), byte2
+	case reflect.Int32:
+		e.wire, e.size = reflect.ValueOf(int32(0)), byte4
+	case reflect.Uint32:
+		e.wire, e.size = reflect.ValueOf(uint32(0)), byte4
+	case reflect.Int64:
+		e.wire, e.size = reflect.ValueOf(int64(0)), byte8
+	case reflect.Uint64:
+		e.wire, e.size = reflect.ValueOf(uint64(0)), byte8
+	default:
+		return fmt.Errorf("this kind can not be underlying type of enum : %v", kind)
+	}
+
+	if len(values) > 0 {
+		e.values = make(map[int64]struct{}, len(values))
+		for _, v := range values {
+			rv := reflect.ValueOf(v)
+			if !rv.IsValid() || rv.Type() != t {
+				return fmt.Errorf("enum value must be %v. but got: %T", t, v)
+			}
+			if _, err := e.bits(rv); err != nil {
+				return err
+			}
+			e.values[enumKey(rv)] = struct{}{}
+		}
+	}
+
+	enumMutex.Lock()
+	defer enumMutex.Unlock()
+	old := enumMap.Load().(map[reflect.Type]*enumInfo)
+	m := make(map[reflect.Type]*enumInfo, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+	m[t] = e
+	enumMap.Store(m)
+	atomic.AddUint64(&enumVersion, 1)
+	return nil
+}
+
+func isIntegerKind(k reflect.Kind) bool {
+	switch k {
+	case
+		reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		return true
+	}
+	return false
+}
+
+func isSignedKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		return true
+	}
+	return false
+}
+
+func findEnum(t reflect.Type) (*enumInfo, bool) {
+	if !isIntegerKind(t.Kind()) || t.PkgPath() == "" {
+		return nil, false
+	}
+	e, ok := enumMap.Load().(map[reflect.Type]*enumInfo)[t]
+	return e, ok
+}
+
+func enumKey(rv reflect.Value) int64 {
+	if isSignedKind(rv.Kind()) {
+		return rv.Int()
+	}
+	return int64(rv.Uint())
+}
+
+// bits converts rv to the underlying type and returns as uint64.
+func (e *enumInfo) bits(rv reflect.Value) (uint64, error) {
+	if isSignedKind(rv.Kind()) {
+		v := rv.Int()
+		if isSignedKind(e.kind) {
+			if !e.wire.OverflowInt(v) {
+				return uint64(v), nil
+			}
+		} else if v >= 0 && !e.wire.OverflowUint(uint64(v)) {
+			return uint64(v), nil
+		}
+	} else {
+		v := rv.Uint()
+		if isSignedKind(e.kind) {
+			if v <= math.MaxInt64 && !e.wire.OverflowInt(int64(v)) {
+				return v, nil
+			}
+		} else if !e.wire.OverflowUint(v) {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("enum value is out of range of %v : %v(%v)", e.kind, rv.Type(), enumKey(rv))
+}
+
+func (d *serializer) serializeEnum(rv reflect.Value, e *enumInfo, offset uint32) (uint32, error) {
+	v, err := e.bits(rv)
+	if err != nil {
+		return 0, err
+	}
+	switch e.size {
+	case byte1:
+		d.writeSize1Uint64(v, offset)
+	case byte2:
+		d.writeSize2Uint64(v, offset)
+	case byte4:
+		d.writeSize4Uint64(v, offset)
+	default:
+		d.writeSize8Uint64(v, offset)
+	}
+	return e.size, nil
+}
+
+func (d *deserializer) deserializeEnum(rv reflect.Value, e *enumInfo, offset uint32) (uint32, error) {
+	var v uint64
+	var o uint32
+	switch e.size {
+	case byte1:
+		var b byte
+		b, o = d.readSize1(offset)
+		v = uint64(b)
+		if isSignedKind(e.kind) {
+			v = uint64(int8(b))
+		}
+	case byte2:
+		var b []byte
+		b, o = d.readSize2(offset)
+		v = uint64(binary.LittleEndian.Uint16(b))
+		if isSignedKind(e.kind) {
+			v = uint64(int16(v))
+		}
+	case byte4:
+		var b []byte
+		b, o = d.readSize4(offset)
+		v = uint64(binary.LittleEndian.Uint32(b))
+		if isSignedKind(e.kind) {
+			v = uint64(int32(v))
+		}
+	default:
+		var b []byte
+		b, o = d.readSize8(offset)
+		v = binary.LittleEndian.Uint64(b)
+	}
+
+	// NOTE : v is sign extended when underlying type is signed
+	negative := isSignedKind(e.kind) && int64(v) < 0
+	if isSignedKind(rv.Kind()) {
+		if (!negative && v > math.MaxInt64) || rv.OverflowInt(int64(v)) {
+			return 0, fmt.Errorf("enum value is out of range of %v : %d", rv.Type(), int64(v))
+		}
+	} else if negative || rv.OverflowUint(v) {
+		return 0, fmt.Errorf("enum value is out of range of %v : %d", rv.Type(), int64(v))
+	}
+
+	// NOTE : the key is as same as enumKey, because v is in range of rv
+	if e.values != nil {
+		if _, ok := e.values[int64(v)]; !ok {
+			return 0, fmt.Errorf("enum value is not defined in %v : %v", rv.Type(), int64(v))
+		}
+	}
+
+	if isSignedKind(rv.Kind()) {
+		rv.SetInt(int64(v))
+	} else {
+		rv.SetUint(v)
+	}
+	return o, nil
+}
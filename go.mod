@@ -0,0 +1,3 @@
+module github.com/shamaton/zeroformatter
+
+go 1.20
@@ -0,0 +1,454 @@
+package zeroformatter
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shamaton/zeroformatter/char"
+	"github.com/shamaton/zeroformatter/datetimeoffset"
+)
+
+// ToJSON analyzes byte data as t and converts it to JSON.
+//
+// Values are converted as follows, and FromJSON converts them back exactly.
+//
+//   - Numbers are JSON numbers. Floats are the shortest form which is parsed to the same value,
+//     and NaN, +Inf and -Inf are "NaN", "Infinity" and "-Infinity".
+//   - Strings are JSON strings. A string which is not valid UTF-8 returns an error,
+//     because JSON can not keep its bytes exactly. Please use []byte or Raw for binary data.
+//   - char.Char is a string of the character. A surrogate is a number of the code unit,
+//     because it can not be in a JSON string alone.
+//   - time.Time is a string of RFC 3339 with nanoseconds in UTC, such as "2017-07-14T02:40:00.000000001Z".
+//   - datetimeoffset.DateTimeOffset is a string of RFC 3339 with nanoseconds and the offset,
+//     such as "2017-07-14T11:40:00.000000001+09:00".
+//   - time.Duration is a string of time.Duration.String, such as "1h2m3.5s".
+//   - Lists and arrays are arrays, and a null list is null.
+//   - Maps whose keys are strings are objects. Other maps are arrays of [key, value] pairs, sorted by the key.
+//   - Structs are objects by the field names. Unknown is not converted.
+//   - Raw is a string of base64.
+func ToJSON(data []byte, t reflect.Type, opts ...Option) ([]byte, error) {
+	if t == nil {
+		return nil, fmt.Errorf("type is nil")
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// data is checked first, because it may be corrupted
+	d := createDeserializer(data, createOptions(opts))
+	if err := d.checkHolder(t); err != nil {
+		return nil, err
+	}
+	rv := reflect.New(t).Elem()
+	if err := d.deserializeHolder(rv); err != nil {
+		return nil, err
+	}
+	b := &bytes.Buffer{}
+	if err := writeJSON(b, rv); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// FromJSON converts JSON to byte data of t. The mapping is as same as ToJSON.
+// Fields which are not in JSON are zero values, and fields which are not in t return an error.
+func FromJSON(data []byte, t reflect.Type, opts ...Option) ([]byte, error) {
+	if t == nil {
+		return nil, fmt.Errorf("type is nil")
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("JSON has data after the value")
+	}
+
+	rv := reflect.New(t).Elem()
+	if err := fromJSON(rv, v, t.String()); err != nil {
+		return nil, err
+	}
+	return createSerializer(createOptions(opts)).serializeHolder(rv)
+}
+
+func writeJSON(b *bytes.Buffer, rv reflect.Value) error {
+	switch rv.Type() {
+	case charType:
+		c := char.Char(rv.Int())
+		if c.IsSurrogate() {
+			b.WriteString(strconv.Itoa(int(c)))
+			return nil
+		}
+		return writeJSONString(b, string(rune(c)))
+
+	case durationType:
+		return writeJSONString(b, time.Duration(rv.Int()).String())
+
+	case dateTimeType:
+		return writeJSONString(b, rv.Interface().(time.Time).UTC().Format(time.RFC3339Nano))
+
+	case dateTimeOffsetType:
+		return writeJSONString(b, rv.Interface().(datetimeoffset.DateTimeOffset).Format(time.RFC3339Nano))
+
+	case rawType:
+		return writeJSONString(b, base64.StdEncoding.EncodeToString(rv.Bytes()))
+	}
+
+	switch rv.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		b.WriteString(strconv.FormatInt(rv.Int(), 10))
+
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		b.WriteString(strconv.FormatUint(rv.Uint(), 10))
+
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		switch {
+		case math.IsNaN(f):
+			b.WriteString(`"NaN"`)
+		case math.IsInf(f, 1):
+			b.WriteString(`"Infinity"`)
+		case math.IsInf(f, -1):
+			b.WriteString(`"-Infinity"`)
+		default:
+			b.WriteString(strconv.FormatFloat(f, 'g', -1, rv.Type().Bits()))
+		}
+
+	case reflect.Bool:
+		b.WriteString(strconv.FormatBool(rv.Bool()))
+
+	case reflect.String:
+		return writeJSONString(b, rv.String())
+
+	case reflect.Array, reflect.Slice:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			b.WriteString("null")
+			return nil
+		}
+		b.WriteByte('[')
+		for i := 0; i < rv.Len(); i++ {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := writeJSON(b, rv.Index(i)); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+
+	case reflect.Map:
+		return writeJSONMap(b, rv)
+
+	case reflect.Struct:
+		b.WriteByte('{')
+		first := true
+		for i := 0; i < rv.NumField(); i++ {
+			f := rv.Type().Field(i)
+			if f.Type == unknownType {
+				continue
+			}
+			if !first {
+				b.WriteByte(',')
+			}
+			first = false
+			if err := writeJSONString(b, fieldName(f)); err != nil {
+				return err
+			}
+			b.WriteByte(':')
+			if err := writeJSON(b, rv.Field(i)); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+
+	case reflect.Ptr:
+		if rv.IsNil() {
+			b.WriteString("null")
+			return nil
+		}
+		return writeJSON(b, rv.Elem())
+
+	default:
+		return fmt.Errorf("this type is not supported : %v", rv.Type())
+	}
+	return nil
+}
+
+func writeJSONMap(b *bytes.Buffer, rv reflect.Value) error {
+	// entries are sorted by the key in JSON
+	type entry struct {
+		key   []byte
+		value reflect.Value
+	}
+	entries := make([]entry, 0, rv.Len())
+	for _, k := range rv.MapKeys() {
+		kb := &bytes.Buffer{}
+		if err := writeJSON(kb, k); err != nil {
+			return err
+		}
+		entries = append(entries, entry{key: kb.Bytes(), value: rv.MapIndex(k)})
+	}
+	sort.Slice(entries, func(i, j int) bool { return bytes.Compare(entries[i].key, entries[j].key) < 0 })
+
+	object := rv.Type().Key().Kind() == reflect.String
+	if object {
+		b.WriteByte('{')
+	} else {
+		b.WriteByte('[')
+	}
+	for i, e := range entries {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if object {
+			b.Write(e.key)
+			b.WriteByte(':')
+		} else {
+			b.WriteByte('[')
+			b.Write(e.key)
+			b.WriteByte(',')
+		}
+		if err := writeJSON(b, e.value); err != nil {
+			return err
+		}
+		if !object {
+			b.WriteByte(']')
+		}
+	}
+	if object {
+		b.WriteByte('}')
+	} else {
+		b.WriteByte(']')
+	}
+	return nil
+}
+
+func writeJSONString(b *bytes.Buffer, s string) error {
+	if !utf8.ValidString(s) {
+		return fmt.Errorf("string is not valid UTF-8 : %q", s)
+	}
+	e := json.NewEncoder(b)
+	e.SetEscapeHTML(false)
+	if err := e.Encode(s); err != nil {
+		return err
+	}
+	// Encode adds a newline
+	b.Truncate(b.Len() - 1)
+	return nil
+}
+
+// fromJSON sets v, which is decoded with UseNumber, into rv.
+func fromJSON(rv reflect.Value, v interface{}, path string) error {
+	// zero value
+	if v == nil {
+		return nil
+	}
+	t := rv.Type()
+
+	switch t {
+	case charType:
+		switch c := v.(type) {
+		case string:
+			r, size := utf8.DecodeRuneInString(c)
+			if size == 0 || size != len(c) || r > rune(char.MaxChar) {
+				return fmt.Errorf("%s : Char must be a character in UTF-16 code unit : %q", path, c)
+			}
+			rv.SetInt(int64(r))
+			return nil
+		case json.Number:
+			u, err := strconv.ParseUint(string(c), 10, 16)
+			if err != nil {
+				return fmt.Errorf("%s : Char must be a code unit : %v", path, c)
+			}
+			rv.SetInt(int64(u))
+			return nil
+		}
+		return fmt.Errorf("%s : Char must be string or number. but got: %T", path, v)
+
+	case durationType, dateTimeType, dateTimeOffsetType, rawType:
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%s : %v must be string. but got: %T", path, t, v)
+		}
+		var value interface{}
+		var err error
+		switch t {
+		case durationType:
+			value, err = time.ParseDuration(s)
+		case dateTimeType:
+			value, err = time.Parse(time.RFC3339Nano, s)
+		case dateTimeOffsetType:
+			var tm time.Time
+			tm, err = time.Parse(time.RFC3339Nano, s)
+			value = datetimeoffset.FromTime(tm)
+		case rawType:
+			var raw []byte
+			raw, err = base64.StdEncoding.DecodeString(s)
+			value = Raw(raw)
+		}
+		if err != nil {
+			return fmt.Errorf("%s : %v", path, err)
+		}
+		rv.Set(reflect.ValueOf(value).Convert(t))
+		return nil
+	}
+
+	switch t.Kind() {
+	case
+		reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		n, ok := v.(json.Number)
+		if !ok {
+			return fmt.Errorf("%s : %v must be number. but got: %T", path, t, v)
+		}
+		var sv reflect.Value
+		if isSignedKind(t.Kind()) {
+			i, err := strconv.ParseInt(string(n), 10, 64)
+			if err != nil {
+				return fmt.Errorf("%s : %v must be integer : %v", path, t, n)
+			}
+			sv = reflect.ValueOf(i)
+		} else {
+			u, err := strconv.ParseUint(string(n), 10, 64)
+			if err != nil {
+				return fmt.Errorf("%s : %v must be unsigned integer : %v", path, t, n)
+			}
+			sv = reflect.ValueOf(u)
+		}
+		return setNumber(rv, sv, path)
+
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch n := v.(type) {
+		case json.Number:
+			var err error
+			f, err = strconv.ParseFloat(string(n), t.Bits())
+			if err != nil {
+				return fmt.Errorf("%s : %v", path, err)
+			}
+		case string:
+			switch n {
+			case "NaN":
+				f = math.NaN()
+			case "Infinity":
+				f = math.Inf(1)
+			case "-Infinity":
+				f = math.Inf(-1)
+			default:
+				return fmt.Errorf("%s : float must be number, NaN, Infinity or -Infinity : %q", path, n)
+			}
+		default:
+			return fmt.Errorf("%s : %v must be number. but got: %T", path, t, v)
+		}
+		rv.SetFloat(f)
+		return nil
+
+	case reflect.Bool:
+		b, ok := v.(bool)
+		if !ok {
+			return fmt.Errorf("%s : %v must be bool. but got: %T", path, t, v)
+		}
+		rv.SetBool(b)
+		return nil
+
+	case reflect.String:
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%s : %v must be string. but got: %T", path, t, v)
+		}
+		rv.SetString(s)
+		return nil
+
+	case reflect.Array, reflect.Slice:
+		l, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s : %v must be array. but got: %T", path, t, v)
+		}
+		if t.Kind() == reflect.Array && len(l) != t.Len() {
+			return fmt.Errorf("%s : array length is different : %d", path, len(l))
+		}
+		if t.Kind() == reflect.Slice {
+			rv.Set(reflect.MakeSlice(t, len(l), len(l)))
+		}
+		for i, e := range l {
+			if err := fromJSON(rv.Index(i), e, fmt.Sprint(path, "[", i, "]")); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		rv.Set(reflect.MakeMap(t))
+		if t.Key().Kind() == reflect.String {
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s : %v must be object. but got: %T", path, t, v)
+			}
+			for k, e := range m {
+				value := reflect.New(t.Elem()).Elem()
+				if err := fromJSON(value, e, fmt.Sprintf("%s[%q]", path, k)); err != nil {
+					return err
+				}
+				rv.SetMapIndex(reflect.ValueOf(k).Convert(t.Key()), value)
+			}
+			return nil
+		}
+
+		l, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s : %v must be array of [key, value]. but got: %T", path, t, v)
+		}
+		for i, e := range l {
+			p := fmt.Sprint(path, "[", i, "]")
+			pair, ok := e.([]interface{})
+			if !ok || len(pair) != 2 {
+				return fmt.Errorf("%s : entry must be [key, value] : %v", p, e)
+			}
+			key := reflect.New(t.Key()).Elem()
+			if err := fromJSON(key, pair[0], p+"[key]"); err != nil {
+				return err
+			}
+			value := reflect.New(t.Elem()).Elem()
+			if err := fromJSON(value, pair[1], p+"[value]"); err != nil {
+				return err
+			}
+			rv.SetMapIndex(key, value)
+		}
+		return nil
+
+	case reflect.Struct:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s : %v must be object. but got: %T", path, t, v)
+		}
+		for name, e := range m {
+			i := schemaFieldIndex(t, name)
+			if i < 0 || t.Field(i).Type == unknownType {
+				return fmt.Errorf("%s : field is not defined : %s", path, name)
+			}
+			if err := fromJSON(rv.Field(i), e, path+"."+name); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Ptr:
+		rv.Set(reflect.New(t.Elem()))
+		return fromJSON(rv.Elem(), v, path)
+	}
+	return fmt.Errorf("%s : this type is not supported : %v", path, t)
+}
@@ -0,0 +1,95 @@
+package zeroformatter
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// LazyList reads elements of serialized []T or [N]T from byte data on demand.
+// Fixed size elements are found by the stride, and variable size elements are found
+// by skipping the previous ones without deserializing.
+// LazyList is not safe for concurrent use.
+type LazyList[T any] struct {
+	*deserializer
+	t      reflect.Type
+	length int
+	stride uint32
+
+	// offsets of variable size elements, which are found so far
+	offsets []uint32
+}
+
+// NewLazyList creates a lazy list of T from serialized []T or [N]T.
+func NewLazyList[T any](data []byte, opts ...Option) (*LazyList[T], error) {
+	return createLazyList[T](createDeserializer(data, createOptions(opts)), 0)
+}
+
+// ListField creates a lazy list of E from the field of view which is []E or [N]E.
+func ListField[E, T any](v *View[T], name string) (*LazyList[E], error) {
+	i, err := v.fieldIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	ft := v.t.Field(i).Type
+	if (ft.Kind() != reflect.Slice && ft.Kind() != reflect.Array) || ft.Elem() != reflect.TypeOf((*E)(nil)).Elem() {
+		return nil, fmt.Errorf("field type is not list of %v : %v", reflect.TypeOf((*E)(nil)).Elem(), ft)
+	}
+	return createLazyList[E](v.deserializer, v.fieldOffset(i))
+}
+
+func createLazyList[T any](ds *deserializer, offset uint32) (*LazyList[T], error) {
+	l, o, err := ds.readLength(offset)
+	if err != nil {
+		return nil, err
+	}
+	// data is null
+	if l < 0 {
+		l = 0
+	}
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	list := &LazyList[T]{deserializer: ds, t: t, length: l}
+	if s, ok := ds.opt.fixedSize(t); ok {
+		if uint64(o)+uint64(s)*uint64(l) > uint64(len(ds.data)) {
+			return nil, fmt.Errorf("list length is wrong at %d : %d", offset, l)
+		}
+		list.stride = s
+	}
+	list.offsets = []uint32{o}
+	return list, nil
+}
+
+// Len returns the number of elements.
+func (l *LazyList[T]) Len() int {
+	return l.length
+}
+
+// Get deserializes the element at index i and returns it.
+func (l *LazyList[T]) Get(i int) (T, error) {
+	var v T
+	if i < 0 || i >= l.length {
+		return v, fmt.Errorf("this index is out of range : %d", i)
+	}
+
+	offset, err := l.offsetOf(i)
+	if err != nil {
+		return v, err
+	}
+	_, err = l.deserialize(reflect.ValueOf(&v).Elem(), offset)
+	return v, err
+}
+
+func (l *LazyList[T]) offsetOf(i int) (uint32, error) {
+	if l.stride > 0 {
+		return l.offsets[0] + l.stride*uint32(i), nil
+	}
+
+	for len(l.offsets) <= i {
+		o, err := l.skip(l.t, l.offsets[len(l.offsets)-1])
+		if err != nil {
+			return 0, err
+		}
+		l.offsets = append(l.offsets, o)
+	}
+	return l.offsets[i], nil
+}
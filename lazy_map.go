@@ -0,0 +1,146 @@
+package zeroformatter
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// LazyMap looks up values of serialized map[K]V from byte data on demand.
+//
+// Dictionary in this package has no hash buckets on the wire, so the first lookup scans entries
+// until the key is found, skipping values without deserializing. From the second lookup,
+// an index from serialized keys to offsets of values is made and used.
+// Only the value which is looked up is deserialized. If the same key is in data twice, the first one is used.
+// LazyMap is safe for concurrent use.
+type LazyMap[K comparable, V any] struct {
+	*deserializer
+	offset uint32
+	length int
+
+	mutex   sync.Mutex
+	lookups int
+	index   map[string]uint32
+}
+
+// NewLazyMap creates a lazy map of K and V from serialized map[K]V.
+func NewLazyMap[K comparable, V any](data []byte, opts ...Option) (*LazyMap[K, V], error) {
+	return createLazyMap[K, V](createDeserializer(data, createOptions(opts)), 0)
+}
+
+// MapField creates a lazy map of K and V from the field of view which is map[K]V.
+func MapField[K comparable, V any, T any](v *View[T], name string) (*LazyMap[K, V], error) {
+	i, err := v.fieldIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	ft := v.t.Field(i).Type
+	mt := reflect.TypeOf((*map[K]V)(nil)).Elem()
+	if ft.Kind() != reflect.Map || ft.Key() != mt.Key() || ft.Elem() != mt.Elem() {
+		return nil, fmt.Errorf("field type is not %v : %v", mt, ft)
+	}
+	return createLazyMap[K, V](v.deserializer, v.fieldOffset(i))
+}
+
+func createLazyMap[K comparable, V any](ds *deserializer, offset uint32) (*LazyMap[K, V], error) {
+	l, o, err := ds.readLength(offset)
+	if err != nil {
+		return nil, err
+	}
+	if l < 0 || uint64(l) > uint64(len(ds.data))-uint64(o) {
+		return nil, fmt.Errorf("map length is wrong at %d : %d", offset, l)
+	}
+	return &LazyMap[K, V]{deserializer: ds, offset: offset, length: l}, nil
+}
+
+// Len returns the number of entries.
+func (m *LazyMap[K, V]) Len() int {
+	return m.length
+}
+
+// Get deserializes the value of key and returns it.
+// If key is not found, the zero value and false are returned.
+func (m *LazyMap[K, V]) Get(key K) (V, bool, error) {
+	var v V
+	k, err := serializeValue(reflect.ValueOf(&key).Elem(), m.opt)
+	if err != nil {
+		return v, false, err
+	}
+
+	m.mutex.Lock()
+	if m.index == nil && m.lookups > 0 {
+		if err := m.createIndex(); err != nil {
+			m.mutex.Unlock()
+			return v, false, err
+		}
+	}
+	m.lookups++
+	index := m.index
+	m.mutex.Unlock()
+
+	var offset uint32
+	var ok bool
+	if index != nil {
+		offset, ok = index[string(k)]
+	} else if offset, ok, err = m.scan(k); err != nil {
+		return v, false, err
+	}
+	if !ok {
+		return v, false, nil
+	}
+	if _, err := m.deserialize(reflect.ValueOf(&v).Elem(), offset); err != nil {
+		return v, false, err
+	}
+	return v, true, nil
+}
+
+// scan returns the offset of the value whose serialized key is k, without allocating keys.
+func (m *LazyMap[K, V]) scan(k []byte) (uint32, bool, error) {
+	kt := reflect.TypeOf((*K)(nil)).Elem()
+	vt := reflect.TypeOf((*V)(nil)).Elem()
+
+	o := m.offset + byte4
+	for i := 0; i < m.length; i++ {
+		ko := o
+		vo, err := m.skip(kt, ko)
+		if err != nil {
+			return 0, false, err
+		}
+		if bytes.Equal(m.data[ko:vo], k) {
+			return vo, true, nil
+		}
+		o, err = m.skip(vt, vo)
+		if err != nil {
+			return 0, false, err
+		}
+	}
+	return 0, false, nil
+}
+
+// createIndex makes the index of all entries. mutex must be locked.
+func (m *LazyMap[K, V]) createIndex() error {
+	kt := reflect.TypeOf((*K)(nil)).Elem()
+	vt := reflect.TypeOf((*V)(nil)).Elem()
+
+	// NOTE : length is not trusted for the capacity, because data may be corrupted
+	index := map[string]uint32{}
+	o := m.offset + byte4
+	for i := 0; i < m.length; i++ {
+		ko := o
+		vo, err := m.skip(kt, ko)
+		if err != nil {
+			return err
+		}
+		o, err = m.skip(vt, vo)
+		if err != nil {
+			return err
+		}
+		// the first one is used for the same key, as same as scan
+		if _, ok := index[string(m.data[ko:vo])]; !ok {
+			index[string(m.data[ko:vo])] = vo
+		}
+	}
+	m.index = index
+	return nil
+}
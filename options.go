@@ -0,0 +1,88 @@
+package zeroformatter
+
+import "time"
+
+// Option changes the behaviour of Serialize and Deserialize.
+// Options which are not related to the process are ignored.
+type Option func(*options)
+
+type options struct {
+	location    *time.Location
+	lenientChar bool
+	checkedInt  bool
+	int64       bool
+	strict      bool
+	canonical   bool
+}
+
+func createOptions(opts []Option) options {
+	o := options{
+		location: time.Local,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithLocation sets the location of time.Time created by deserializing.
+//
+// DateTime in ZeroFormatter is an instant (seconds and nanos from unix epoch in UTC),
+// which is the same as what C# DateTime.ToUniversalTime gives, and has no Kind or location.
+// So deserialized time.Time equals the serialized one by time.Time.Equal,
+// but its location is decided by this option. Default is time.Local.
+//
+//	zeroformatter.Deserialize(&r, b, zeroformatter.WithLocation(time.UTC))
+func WithLocation(loc *time.Location) Option {
+	return func(o *options) {
+		if loc == nil {
+			loc = time.UTC
+		}
+		o.location = loc
+	}
+}
+
+// WithLenientChar makes serializing char.Char which does not fit in a UTF-16 code unit
+// write char.ReplacementChar instead of returning an error.
+func WithLenientChar() Option {
+	return func(o *options) {
+		o.lenientChar = true
+	}
+}
+
+// WithCheckedInt makes serializing int and uint return an error
+// when the value does not fit in Int32 and UInt32, instead of truncating.
+func WithCheckedInt() Option {
+	return func(o *options) {
+		o.checkedInt = true
+	}
+}
+
+// WithInt64 maps int and uint to Int64 and UInt64 instead of Int32 and UInt32.
+// This is not compatible with C#, so use this in both of serializing and deserializing
+// only when all of applications are written in golang.
+func WithInt64() Option {
+	return func(o *options) {
+		o.int64 = true
+	}
+}
+
+// WithStrict makes deserializing reject byte data which Serialize never writes.
+// Bool which is not 0 or 1, bytes after a primitive or a collection at the top level,
+// and offsets in the object header which are not contiguous return an error.
+// An offset must be the end of the previous field, so offsets pointing inside the header,
+// overlapping other fields or leaving unused bytes are rejected.
+// Raw and fields of undefined indexes kept by Unknown end at the next offset.
+func WithStrict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithCanonical makes serializing write map entries in the order of keys, which is defined by compareKeys.
+// So the same values are always serialized to the same bytes, for hashing and signing.
+func WithCanonical() Option {
+	return func(o *options) {
+		o.canonical = true
+	}
+}
@@ -0,0 +1,217 @@
+package zeroformatter
+
+import (
+	"fmt"
+	"reflect"
+	"sync/atomic"
+)
+
+// plan is the result of analyzing a type before serializing and deserializing.
+type plan struct {
+	t      reflect.Type
+	object bool
+
+	// size of byte data, when the size is always same
+	fixed bool
+	size  uint32
+	// enumVersion when size is computed, because sizes of enums can be changed by RegisterEnum
+	enumVersion uint64
+
+	// fields of the top level struct, and the object header when the size is always same
+	fields []planField
+	header []byte
+}
+
+// planField is the result of analyzing a field of the top level struct.
+type planField struct {
+	// size of the field and the offset in byte data, when the size is always same
+	fixed  bool
+	size   uint32
+	offset uint32
+}
+
+func compile(t reflect.Type, opt options) (*plan, error) {
+	if t == nil {
+		return nil, fmt.Errorf("type is nil")
+	}
+	for _, p := range checkType(t) {
+		if !p.NilRisk {
+			return nil, p
+		}
+	}
+
+	// as same as Serialize, pointer is dereferenced
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	p := &plan{t: t, object: isInlineStruct(t), enumVersion: atomic.LoadUint64(&enumVersion)}
+	if !p.object {
+		p.size, p.fixed = opt.fixedSize(t)
+		return p, nil
+	}
+
+	nf := numIndex(t)
+	p.fixed = !hasUnknown(t)
+	p.size = uint32(2+nf) * byte4
+	p.fields = make([]planField, nf)
+	for i := range p.fields {
+		f := &p.fields[i]
+		f.size, f.fixed = opt.fixedSize(t.Field(i).Type)
+		f.offset = p.size
+		p.fixed = p.fixed && f.fixed
+		p.size += f.size
+	}
+
+	// the object header is always same too
+	if p.fixed {
+		s := createSerializer(opt)
+		s.create = make([]byte, uint32(2+nf)*byte4)
+		s.writeSize4Uint32(p.size, 0)
+		s.writeSize4Int(nf-1, byte4)
+		for i, f := range p.fields {
+			s.writeSize4Uint32(f.offset, uint32(2+i)*byte4)
+		}
+		p.header = s.create
+	}
+	return p, nil
+}
+
+// isValid returns false if sizes in p are changed by RegisterEnum after compiling.
+func (p *plan) isValid() bool {
+	return p.enumVersion == atomic.LoadUint64(&enumVersion)
+}
+
+// isFixed returns true if the size of byte data is always p.size.
+func (p *plan) isFixed() bool {
+	return p.fixed && p.isValid()
+}
+
+// serializeStruct converts rv to byte datas with sizes and offsets in p.
+func (p *plan) serializeStruct(d *serializer, rv reflect.Value) ([]byte, error) {
+	// write fields at the known offsets
+	if p.fixed {
+		d.create = make([]byte, p.size)
+		copy(d.create, p.header)
+		for i, f := range p.fields {
+			if _, err := d.serializeField(rv.Field(i), f.offset); err != nil {
+				return nil, err
+			}
+		}
+		return d.create, nil
+	}
+
+	// calculate sizes of fields which can be changed only
+	unknown := unknownOf(rv)
+	startOffset := uint32(2+len(p.fields)+len(unknown)) * byte4
+	size := startOffset
+	for i, f := range p.fields {
+		s := f.size
+		if !f.fixed {
+			var err error
+			if s, err = d.fieldSize(rv.Field(i)); err != nil {
+				return nil, err
+			}
+		}
+		size += s
+	}
+	for _, u := range unknown {
+		size += uint32(len(u))
+	}
+	d.create = make([]byte, size)
+	err := d.serializeStruct(rv, startOffset, size)
+	return d.create, err
+}
+
+// checkType walks t statically, and returns all problems.
+func checkType(t reflect.Type) []Problem {
+	c := &typeChecker{visiting: map[reflect.Type]int{}}
+	c.check(t, t.String(), 0)
+	return c.problems
+}
+
+type typeChecker struct {
+	problems []Problem
+	// visiting has structs in the walk, with the number of slices and maps above them
+	visiting map[reflect.Type]int
+	// empties is the number of slices and maps above the current type, which can be empty
+	empties int
+}
+
+// check checks t at path. level is 0 for holder, 1 for fields of the top level struct.
+func (c *typeChecker) check(t reflect.Type, path string, level int) {
+	if _, ok := findEnum(t); ok {
+		return
+	}
+
+	switch t.Kind() {
+	case
+		reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint,
+		reflect.Float32, reflect.Float64,
+		reflect.Bool, reflect.String:
+
+	case reflect.Array, reflect.Slice:
+		if t == rawType {
+			if level > 1 {
+				c.add(path, "Raw is supported as a field of the top level struct only")
+			}
+			return
+		}
+		if t.Kind() == reflect.Slice {
+			c.empties++
+			defer func() { c.empties-- }()
+		}
+		c.check(t.Elem(), path+"[]", level+2)
+
+	case reflect.Map:
+		c.empties++
+		defer func() { c.empties-- }()
+		c.check(t.Key(), path+"[key]", level+2)
+		c.check(t.Elem(), path+"[value]", level+2)
+
+	case reflect.Struct:
+		if t == dateTimeType || t == dateTimeOffsetType || t == unknownType {
+			return
+		}
+		// recursive type
+		if _, ok := c.visiting[t]; ok {
+			return
+		}
+		c.visiting[t] = c.empties
+		defer delete(c.visiting, t)
+
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			p := path + "." + f.Name
+			if f.PkgPath != "" {
+				c.add(p, "unexported field is not supported")
+				continue
+			}
+			if f.Type == unknownType && (level > 0 || i != t.NumField()-1) {
+				c.add(p, "Unknown is supported as the last field of the top level struct only")
+				continue
+			}
+			c.check(f.Type, p, level+1)
+		}
+
+	case reflect.Ptr:
+		// NOTE : the struct can not end without nil pointer, if no slice or map is between them
+		if e, ok := c.visiting[t.Elem()]; ok && e == c.empties {
+			c.add(path, "recursive pointer is not supported, because the last one is nil")
+			return
+		}
+		// holder can be nil in deserializing
+		if level > 0 {
+			c.problems = append(c.problems, Problem{Path: path, Reason: "pointer returns an error in serializing if it is nil", NilRisk: true})
+		}
+		c.check(t.Elem(), path, level)
+
+	default:
+		c.add(path, fmt.Sprint("this type is not supported : ", t))
+	}
+}
+
+func (c *typeChecker) add(path string, reason string) {
+	c.problems = append(c.problems, Problem{Path: path, Reason: reason})
+}
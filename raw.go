@@ -0,0 +1,57 @@
+package zeroformatter
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Raw is serialized byte data of a value, which is not deserialized.
+// It is like json.RawMessage. Deserializing sets the exact byte data of the field,
+// and serializing writes it as it is. So values whose types are unknown can be passed through.
+//
+// Raw needs the object header to know the size of the data,
+// so Raw is supported as a field of the top level struct, or as the top level value.
+type Raw []byte
+
+var rawType = reflect.TypeOf(Raw(nil))
+
+var errRawPosition = errors.New("Raw is supported as a field of the top level struct only")
+
+// fieldRegion returns the start and the end of the field at index in the object.
+// The field ends at the nearest offset behind, or end of data.
+func (d *deserializer) fieldRegion(index int) (uint32, uint32, error) {
+	lastIndex, err := d.readHeader()
+	if err != nil {
+		return 0, 0, err
+	}
+	if index < 0 || uint32(index) > lastIndex {
+		return 0, 0, fmt.Errorf("this index is out of range : %d", index)
+	}
+
+	start := d.fieldOffset(index)
+	end := uint32(len(d.data))
+	for i := 0; i <= int(lastIndex); i++ {
+		if o := d.fieldOffset(i); start < o && o < end {
+			end = o
+		}
+	}
+	if start < uint32(3+lastIndex)*byte4 || start > end {
+		return 0, 0, fmt.Errorf("field offset is wrong : %d", start)
+	}
+	return start, end, nil
+}
+
+// deserializeAt deserializes the field at index in the object and sets into rv.
+func (d *deserializer) deserializeAt(rv reflect.Value, index int) error {
+	if rv.Type() == rawType {
+		start, end, err := d.fieldRegion(index)
+		if err != nil {
+			return err
+		}
+		rv.SetBytes(append([]byte{}, d.data[start:end]...))
+		return nil
+	}
+	_, err := d.deserialize(rv, d.fieldOffset(index))
+	return err
+}
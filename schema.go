@@ -0,0 +1,380 @@
+package zeroformatter
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+	"unicode"
+
+	"github.com/shamaton/zeroformatter/char"
+	"github.com/shamaton/zeroformatter/datetimeoffset"
+)
+
+// Schema is types which are defined in the schema text.
+// Each object is built as a golang struct type, so it is serialized by the same rules as defined structs.
+//
+// The schema text has objects, which have fields with the index, the ZeroFormatter type name and the name.
+// Objects can be used as a type of fields, then they are serialized inline as same as nested structs.
+//
+//	// comment
+//	object Message {
+//		0: Int32 ID
+//		1: String Name
+//		2: List<Child> Children
+//		3: Dictionary<String, DateTimeOffset> Updated
+//	}
+//
+//	object Child {
+//		0: Char Initial
+//		1: Double Score
+//	}
+//
+// Types are SByte, Byte, Int16, UInt16, Int32, UInt32, Int64, UInt64, Single, Double, Boolean, String,
+// Char, DateTime, DateTimeOffset, TimeSpan, List<T>, Dictionary<K, V> and objects.
+// Indexes must be from 0 without gaps. Keys of Dictionary must be primitive types.
+type Schema struct {
+	objects map[string]*schemaObject
+	types   map[string]reflect.Type
+}
+
+type schemaObject struct {
+	name   string
+	line   int
+	fields []schemaField
+}
+
+type schemaField struct {
+	index int
+	name  string
+	typ   *schemaType
+	line  int
+}
+
+type schemaType struct {
+	name string
+	args []*schemaType
+}
+
+func (t *schemaType) String() string {
+	if len(t.args) == 0 {
+		return t.name
+	}
+	s := t.name + "<"
+	for i, a := range t.args {
+		if i > 0 {
+			s += ", "
+		}
+		s += a.String()
+	}
+	return s + ">"
+}
+
+var schemaPrimitives = map[string]reflect.Type{
+	"SByte":          reflect.TypeOf(int8(0)),
+	"Byte":           reflect.TypeOf(uint8(0)),
+	"Int16":          reflect.TypeOf(int16(0)),
+	"UInt16":         reflect.TypeOf(uint16(0)),
+	"Int32":          reflect.TypeOf(int32(0)),
+	"UInt32":         reflect.TypeOf(uint32(0)),
+	"Int64":          reflect.TypeOf(int64(0)),
+	"UInt64":         reflect.TypeOf(uint64(0)),
+	"Single":         reflect.TypeOf(float32(0)),
+	"Double":         reflect.TypeOf(float64(0)),
+	"Boolean":        reflect.TypeOf(false),
+	"String":         reflect.TypeOf(""),
+	"Char":           reflect.TypeOf(char.Char(0)),
+	"DateTime":       reflect.TypeOf(time.Time{}),
+	"DateTimeOffset": reflect.TypeOf(datetimeoffset.DateTimeOffset{}),
+	"TimeSpan":       reflect.TypeOf(time.Duration(0)),
+}
+
+// ParseSchema parses the schema text and builds types of all objects.
+func ParseSchema(text string) (*Schema, error) {
+	p := &schemaParser{tokens: tokenizeSchema(text)}
+	s := &Schema{objects: map[string]*schemaObject{}, types: map[string]reflect.Type{}}
+
+	for !p.end() {
+		o, err := p.parseObject()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := s.objects[o.name]; ok {
+			return nil, fmt.Errorf("line %d : object %s is already defined", o.line, o.name)
+		}
+		if _, ok := schemaPrimitives[o.name]; ok || o.name == "List" || o.name == "Dictionary" {
+			return nil, fmt.Errorf("line %d : %s is a reserved type name", o.line, o.name)
+		}
+		s.objects[o.name] = o
+	}
+
+	names := make([]string, 0, len(s.objects))
+	for name := range s.objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := s.build(name, map[string]bool{}); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Names returns names of objects in the schema.
+func (s *Schema) Names() []string {
+	names := make([]string, 0, len(s.types))
+	for name := range s.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Type returns the golang struct type of the object.
+// Fields are named F0, F1... by the index, and names in the schema are in the tag `zf:"name"`.
+func (s *Schema) Type(name string) (reflect.Type, bool) {
+	t, ok := s.types[name]
+	return t, ok
+}
+
+// fieldName returns the name in the schema if f is built by Schema, otherwise the field name.
+func fieldName(f reflect.StructField) string {
+	if name := f.Tag.Get("zf"); name != "" {
+		return name
+	}
+	return f.Name
+}
+
+func (s *Schema) build(name string, visiting map[string]bool) (reflect.Type, error) {
+	if t, ok := s.types[name]; ok {
+		return t, nil
+	}
+	o := s.objects[name]
+	if visiting[name] {
+		return nil, fmt.Errorf("line %d : recursive object is not supported : %s", o.line, name)
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	fields := make([]reflect.StructField, len(o.fields))
+	for i, f := range o.fields {
+		t, err := s.resolve(f.typ, f.line, visiting)
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = reflect.StructField{
+			Name: fmt.Sprint("F", f.index),
+			Type: t,
+			Tag:  reflect.StructTag(fmt.Sprintf(`zf:%q`, f.name)),
+		}
+	}
+	t := reflect.StructOf(fields)
+	s.types[name] = t
+	return t, nil
+}
+
+func (s *Schema) resolve(st *schemaType, line int, visiting map[string]bool) (reflect.Type, error) {
+	switch st.name {
+	case "List":
+		if len(st.args) != 1 {
+			return nil, fmt.Errorf("line %d : List must have 1 type argument : %v", line, st)
+		}
+		e, err := s.resolve(st.args[0], line, visiting)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.SliceOf(e), nil
+
+	case "Dictionary":
+		if len(st.args) != 2 {
+			return nil, fmt.Errorf("line %d : Dictionary must have 2 type arguments : %v", line, st)
+		}
+		k, ok := schemaPrimitives[st.args[0].name]
+		if !ok || len(st.args[0].args) > 0 {
+			return nil, fmt.Errorf("line %d : key of Dictionary must be primitive : %v", line, st.args[0])
+		}
+		v, err := s.resolve(st.args[1], line, visiting)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.MapOf(k, v), nil
+	}
+
+	if len(st.args) > 0 {
+		return nil, fmt.Errorf("line %d : %s can not have type arguments", line, st.name)
+	}
+	if t, ok := schemaPrimitives[st.name]; ok {
+		return t, nil
+	}
+	if _, ok := s.objects[st.name]; ok {
+		return s.build(st.name, visiting)
+	}
+	return nil, fmt.Errorf("line %d : undefined type : %s", line, st.name)
+}
+
+type schemaToken struct {
+	text string
+	line int
+}
+
+func tokenizeSchema(text string) []schemaToken {
+	tokens := []schemaToken{}
+	rs := []rune(text)
+	line := 1
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case r == '\n':
+			line++
+			i++
+		case unicode.IsSpace(r):
+			i++
+		case r == '/' && i+1 < len(rs) && rs[i+1] == '/':
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+		case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			start := i
+			for i < len(rs) && (rs[i] == '_' || unicode.IsLetter(rs[i]) || unicode.IsDigit(rs[i])) {
+				i++
+			}
+			tokens = append(tokens, schemaToken{text: string(rs[start:i]), line: line})
+		default:
+			tokens = append(tokens, schemaToken{text: string(r), line: line})
+			i++
+		}
+	}
+	return tokens
+}
+
+type schemaParser struct {
+	tokens []schemaToken
+	pos    int
+}
+
+func (p *schemaParser) end() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *schemaParser) peek() schemaToken {
+	if p.end() {
+		line := 1
+		if len(p.tokens) > 0 {
+			line = p.tokens[len(p.tokens)-1].line
+		}
+		return schemaToken{line: line}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *schemaParser) next() schemaToken {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *schemaParser) expect(text string) error {
+	if t := p.next(); t.text != text {
+		return fmt.Errorf("line %d : %q is expected. but got: %q", t.line, text, t.text)
+	}
+	return nil
+}
+
+func (p *schemaParser) ident() (schemaToken, error) {
+	t := p.next()
+	if t.text == "" || !(t.text[0] == '_' || unicode.IsLetter([]rune(t.text)[0])) {
+		return t, fmt.Errorf("line %d : name is expected. but got: %q", t.line, t.text)
+	}
+	return t, nil
+}
+
+func (p *schemaParser) parseObject() (*schemaObject, error) {
+	if err := p.expect("object"); err != nil {
+		return nil, err
+	}
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	o := &schemaObject{name: name.text, line: name.line}
+	for p.peek().text != "}" {
+		if p.end() {
+			return nil, fmt.Errorf("line %d : object %s is not closed", o.line, o.name)
+		}
+		f, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+		o.fields = append(o.fields, f)
+	}
+	p.next()
+
+	// indexes must be from 0 without gaps
+	sort.Slice(o.fields, func(i, j int) bool { return o.fields[i].index < o.fields[j].index })
+	names := map[string]bool{}
+	for i, f := range o.fields {
+		if f.index != i {
+			return nil, fmt.Errorf("line %d : index must be %d in %s. but got: %d", f.line, i, o.name, f.index)
+		}
+		if names[f.name] {
+			return nil, fmt.Errorf("line %d : field %s is already defined in %s", f.line, f.name, o.name)
+		}
+		names[f.name] = true
+	}
+	return o, nil
+}
+
+func (p *schemaParser) parseField() (schemaField, error) {
+	t := p.next()
+	index, err := strconv.Atoi(t.text)
+	if err != nil || index < 0 {
+		return schemaField{}, fmt.Errorf("line %d : index is expected. but got: %q", t.line, t.text)
+	}
+	if err := p.expect(":"); err != nil {
+		return schemaField{}, err
+	}
+	typ, err := p.parseType()
+	if err != nil {
+		return schemaField{}, err
+	}
+	name, err := p.ident()
+	if err != nil {
+		return schemaField{}, err
+	}
+	if p.peek().text == ";" {
+		p.next()
+	}
+	return schemaField{index: index, name: name.text, typ: typ, line: t.line}, nil
+}
+
+func (p *schemaParser) parseType() (*schemaType, error) {
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	st := &schemaType{name: name.text}
+	if p.peek().text != "<" {
+		return st, nil
+	}
+	p.next()
+	for {
+		a, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		st.args = append(st.args, a)
+		t := p.next()
+		if t.text == ">" {
+			return st, nil
+		}
+		if t.text != "," {
+			return nil, fmt.Errorf("line %d : \",\" or \">\" is expected. but got: %q", t.line, t.text)
+		}
+	}
+}
@@ -5,8 +5,9 @@ import (
 	"fmt"
 	"math"
 	"reflect"
-	"unicode/utf16"
 	"unsafe"
+
+	"github.com/shamaton/zeroformatter/char"
 )
 
 const (
@@ -18,21 +19,23 @@ const (
 
 type serializer struct {
 	create []byte
+	opt    options
 
 	queueMapKey   [][]reflect.Value
 	queueMapValue []reflect.Value
 }
 
-func createSerializer() *serializer {
+func createSerializer(opt options) *serializer {
 	return &serializer{
+		opt:           opt,
 		queueMapKey:   [][]reflect.Value{},
 		queueMapValue: []reflect.Value{},
 	}
 }
 
 // Serialize analyzes holder and converts to byte datas.
-func Serialize(holder interface{}) ([]byte, error) {
-	d := createSerializer()
+func Serialize(holder interface{}, opts ...Option) ([]byte, error) {
+	d := createSerializer(createOptions(opts))
 
 	t := reflect.ValueOf(holder)
 	if t.Kind() == reflect.Ptr {
@@ -41,30 +44,71 @@ func Serialize(holder interface{}) ([]byte, error) {
 			t = t.Elem()
 		}
 	}
+	return d.serializeHolder(t)
+}
 
-	var err error
+// serializeHolder converts t, which is holder or holder points to, to byte datas.
+func (d *serializer) serializeHolder(t reflect.Value) ([]byte, error) {
 	if t.Kind() == reflect.Struct && !isDateTime(t) && !isDateTimeOffset(t) {
-		startOffset := uint32(2+t.NumField()) * byte4
+		unknown := unknownOf(t)
+		startOffset := uint32(2+numIndex(t.Type())+len(unknown)) * byte4
 
-		dataPartSize, _ := d.calcSize(t)
+		dataPartSize := uint32(0)
+		for i := 0; i < t.NumField(); i++ {
+			s, err := d.fieldSize(t.Field(i))
+			if err != nil {
+				return nil, err
+			}
+			dataPartSize += s
+		}
+		for _, u := range unknown {
+			dataPartSize += uint32(len(u))
+		}
 		size := startOffset + dataPartSize
 		d.create = make([]byte, size)
 
-		err = d.serializeStruct(t, startOffset, size)
-	} else {
-		size, _ := d.calcSize(t)
-		d.create = make([]byte, size)
-		_, err = d.serialize(t, 0)
+		err := d.serializeStruct(t, startOffset, size)
+		return d.create, err
 	}
+	return serializeValue(t, d.opt)
+}
+
+// serializeValue converts rv to byte datas without the object header.
+// rv is the top level value or a field of the top level struct, so Raw is written as it is.
+func serializeValue(rv reflect.Value, opt options) ([]byte, error) {
+	d := createSerializer(opt)
+	size, err := d.fieldSize(rv)
+	if err != nil {
+		return nil, err
+	}
+	d.create = make([]byte, size)
+	if _, err := d.serializeField(rv, 0); err != nil {
+		return nil, err
+	}
+	return d.create, nil
+}
+
+// fieldSize returns the size of the field of the top level struct.
+func (d *serializer) fieldSize(rv reflect.Value) (uint32, error) {
+	if rv.Type() == rawType {
+		return uint32(rv.Len()), nil
+	}
+	return d.calcSize(rv)
+}
 
-	return d.create, err
+// serializeField writes the field of the top level struct at offset, and returns the size.
+func (d *serializer) serializeField(rv reflect.Value, offset uint32) (uint32, error) {
+	if rv.Type() == rawType {
+		return uint32(copy(d.create[offset:], rv.Bytes())), nil
+	}
+	return d.serialize(rv, offset)
 }
 
 func (d *serializer) serializeStruct(rv reflect.Value, offset uint32, size uint32) error {
-	nf := rv.NumField()
+	nf := numIndex(rv.Type())
 	index := 2 * byte4
 	for i := 0; i < nf; i++ {
-		s, err := d.serialize(rv.Field(i), offset)
+		s, err := d.serializeField(rv.Field(i), offset)
 		if err != nil {
 			return err
 		}
@@ -73,10 +117,18 @@ func (d *serializer) serializeStruct(rv reflect.Value, offset uint32, size uint3
 		index += byte4
 		offset += s
 	}
+	// unknown fields as they are
+	unknown := unknownOf(rv)
+	for _, u := range unknown {
+		d.writeSize4Uint32(offset, index)
+		copy(d.create[offset:], u)
+		index += byte4
+		offset += uint32(len(u))
+	}
 	// size
 	d.create[0], d.create[1], d.create[2], d.create[3] = byte(size), byte(size>>8), byte(size>>16), byte(size>>24)
 	// last index
-	li := nf - 1
+	li := nf + len(unknown) - 1
 	d.create[4], d.create[5], d.create[6], d.create[7] = byte(li), byte(li>>8), byte(li>>16), byte(li>>24)
 	return nil
 }
@@ -105,6 +157,10 @@ func (d *serializer) isFixedSize(rv reflect.Value) bool {
 func (d *serializer) calcSize(rv reflect.Value) (uint32, error) {
 	ret := uint32(0)
 
+	if e, ok := findEnum(rv.Type()); ok {
+		return e.size, nil
+	}
+
 	switch rv.Kind() {
 	case reflect.Int8:
 		ret = byte1
@@ -120,7 +176,11 @@ func (d *serializer) calcSize(rv reflect.Value) (uint32, error) {
 		}
 
 	case reflect.Int:
-		ret = byte4
+		if d.opt.int64 {
+			ret = byte8
+		} else {
+			ret = byte4
+		}
 
 	case reflect.Int64:
 		if isDuration(rv) {
@@ -135,9 +195,16 @@ func (d *serializer) calcSize(rv reflect.Value) (uint32, error) {
 	case reflect.Uint16:
 		ret = byte2
 
-	case reflect.Uint32, reflect.Uint:
+	case reflect.Uint32:
 		ret = byte4
 
+	case reflect.Uint:
+		if d.opt.int64 {
+			ret = byte8
+		} else {
+			ret = byte4
+		}
+
 	case reflect.Uint64:
 		ret = byte8
 
@@ -155,6 +222,9 @@ func (d *serializer) calcSize(rv reflect.Value) (uint32, error) {
 		ret = l + byte4
 
 	case reflect.Array, reflect.Slice:
+		if rv.Type() == rawType {
+			return 0, errRawPosition
+		}
 		l := rv.Len()
 		if l > 0 {
 			ret += byte4
@@ -184,6 +254,8 @@ func (d *serializer) calcSize(rv reflect.Value) (uint32, error) {
 			ret = byte4 + byte8 + byte2
 		} else if isDateTime(rv) {
 			ret = byte4 + byte8
+		} else if rv.Type() == unknownType {
+			// added by top level struct
 		} else {
 			for i := 0; i < rv.NumField(); i++ {
 				s, err := d.calcSize(rv.Field(i))
@@ -202,23 +274,29 @@ func (d *serializer) calcSize(rv reflect.Value) (uint32, error) {
 		if l < 1 {
 			return ret, nil
 		}
-		// check fixed type
-		keys := rv.MapKeys()
+		// NOTE : MapIndex can not find NaN keys, so entries are taken by iterator
+		keys := make([]reflect.Value, 0, l)
+		values := make([]reflect.Value, 0, l)
+		iter := rv.MapRange()
+		for iter.Next() {
+			keys = append(keys, iter.Key())
+			values = append(values, iter.Value())
+		}
+		if d.opt.canonical {
+			sortEntries(keys, values)
+		}
 
 		d.queueMapKey = append(d.queueMapKey, keys)
+		d.queueMapValue = append(d.queueMapValue, values...)
 
+		// check fixed type
 		isFixedKey := d.isFixedSize(keys[0])
 		if isFixedKey {
 			sizeK, err := d.calcSize(keys[0])
 			if err != nil {
 				return 0, err
 			}
-			startI := len(d.queueMapValue)
-			add := make([]reflect.Value, l)
-			d.queueMapValue = append(d.queueMapValue, add...)
-			for i, k := range keys {
-				value := rv.MapIndex(k)
-				d.queueMapValue[startI+i] = value
+			for _, value := range values {
 				sizeV, err := d.calcSize(value)
 				if err != nil {
 					return 0, err
@@ -227,17 +305,12 @@ func (d *serializer) calcSize(rv reflect.Value) (uint32, error) {
 			}
 
 		} else {
-			startI := len(d.queueMapValue)
-			add := make([]reflect.Value, l)
-			d.queueMapValue = append(d.queueMapValue, add...)
 			for i, k := range keys {
 				sizeK, err := d.calcSize(k)
 				if err != nil {
 					return 0, err
 				}
-				value := rv.MapIndex(k)
-				d.queueMapValue[startI+i] = value
-				sizeV, err := d.calcSize(value)
+				sizeV, err := d.calcSize(values[i])
 				if err != nil {
 					return 0, err
 				}
@@ -265,6 +338,10 @@ func (d *serializer) calcSize(rv reflect.Value) (uint32, error) {
 func (d *serializer) serialize(rv reflect.Value, offset uint32) (uint32, error) {
 	size := uint32(0)
 
+	if e, ok := findEnum(rv.Type()); ok {
+		return d.serializeEnum(rv, e, offset)
+	}
+
 	switch rv.Kind() {
 	case reflect.Int8:
 		d.writeSize1Int64(rv.Int(), offset)
@@ -276,12 +353,15 @@ func (d *serializer) serialize(rv reflect.Value, offset uint32) (uint32, error)
 
 	case reflect.Int32:
 		if isChar(rv) {
-
-			// rune [ushort(2)]
-			enc := utf16.Encode([]rune{int32(rv.Int())})
-			v := enc[0]
-			d.create[offset+0] = byte(v)
-			d.create[offset+1] = byte(v >> 8)
+			// char [ushort(2)]
+			v := char.Char(rv.Int())
+			if !v.IsValid() {
+				if !d.opt.lenientChar {
+					return 0, fmt.Errorf("char does not fit in a UTF-16 code unit : %#U", rune(v))
+				}
+				v = char.ReplacementChar
+			}
+			d.writeSize2Uint64(uint64(v), offset)
 			size += byte2
 		} else {
 			d.writeSize4Int64(rv.Int(), offset)
@@ -289,8 +369,17 @@ func (d *serializer) serialize(rv reflect.Value, offset uint32) (uint32, error)
 		}
 
 	case reflect.Int:
-		d.writeSize4Int64(rv.Int(), offset)
-		size += byte4
+		v := rv.Int()
+		if d.opt.int64 {
+			d.writeSize8Int64(v, offset)
+			size += byte8
+		} else {
+			if d.opt.checkedInt && (v < math.MinInt32 || v > math.MaxInt32) {
+				return 0, fmt.Errorf("int value does not fit in Int32 : %d", v)
+			}
+			d.writeSize4Int64(v, offset)
+			size += byte4
+		}
 
 	case reflect.Int64:
 		if isDuration(rv) {
@@ -318,10 +407,23 @@ func (d *serializer) serialize(rv reflect.Value, offset uint32) (uint32, error)
 		d.writeSize2Uint64(rv.Uint(), offset)
 		size += byte2
 
-	case reflect.Uint32, reflect.Uint:
+	case reflect.Uint32:
 		d.writeSize4Uint64(rv.Uint(), offset)
 		size += byte4
 
+	case reflect.Uint:
+		v := rv.Uint()
+		if d.opt.int64 {
+			d.writeSize8Uint64(v, offset)
+			size += byte8
+		} else {
+			if d.opt.checkedInt && v > math.MaxUint32 {
+				return 0, fmt.Errorf("uint value does not fit in UInt32 : %d", v)
+			}
+			d.writeSize4Uint64(v, offset)
+			size += byte4
+		}
+
 	case reflect.Uint64:
 		d.writeSize8Uint64(rv.Uint(), offset)
 		size += byte8
@@ -360,6 +462,9 @@ func (d *serializer) serialize(rv reflect.Value, offset uint32) (uint32, error)
 		size += l
 
 	case reflect.Array, reflect.Slice:
+		if rv.Type() == rawType {
+			return 0, errRawPosition
+		}
 		l := rv.Len()
 		if l > 0 {
 			d.writeSize4Int(l, offset)
@@ -383,14 +488,17 @@ func (d *serializer) serialize(rv reflect.Value, offset uint32) (uint32, error)
 	case reflect.Struct:
 		if isDateTimeOffset(rv) {
 
-			// offset
+			// offset (signed minutes)
 			rets := rv.MethodByName("Zone").Call([]reflect.Value{})
 			_, offSec := rets[0] /*name*/, rets[1].Int() /*offset*/
 			offMin := offSec / 60
+			if offMin < math.MinInt16 || offMin > math.MaxInt16 {
+				return 0, fmt.Errorf("offset is out of range : %d minutes", offMin)
+			}
 
-			// seconds
+			// seconds (clock time at the offset)
 			rets = rv.MethodByName("Unix").Call([]reflect.Value{})
-			seconds := rets[0].Int() + offSec
+			seconds := rets[0].Int() + offMin*60
 
 			// nanos
 			rets = rv.MethodByName("Nanosecond").Call([]reflect.Value{})
@@ -422,6 +530,8 @@ func (d *serializer) serialize(rv reflect.Value, offset uint32) (uint32, error)
 			nsec := rets[0].Int()
 			d.writeSize4Int64(nsec, offset)
 			size += byte4
+		} else if rv.Type() == unknownType {
+			// written by serializeStruct
 		} else {
 			for i := 0; i < rv.NumField(); i++ {
 				s, err := d.serialize(rv.Field(i), offset)
@@ -444,9 +554,12 @@ func (d *serializer) serialize(rv reflect.Value, offset uint32) (uint32, error)
 			return size, nil
 		}
 
+		// NOTE : maps in values use the queue, so this map is dequeued first
 		keys := d.queueMapKey[0]
 		keysLen := len(keys)
 		values := d.queueMapValue[:keysLen]
+		d.queueMapKey = d.queueMapKey[1:]
+		d.queueMapValue = d.queueMapValue[keysLen:]
 
 		for i, k := range keys {
 			addOffByK, err := d.serialize(k, offset)
@@ -461,15 +574,6 @@ func (d *serializer) serialize(rv reflect.Value, offset uint32) (uint32, error)
 			size += addOffByK + addOffByV
 		}
 
-		// update queue
-		if len(d.queueMapKey) > 0 {
-			d.queueMapKey = d.queueMapKey[1:]
-			d.queueMapValue = d.queueMapValue[keysLen:]
-		} else {
-			d.queueMapKey = d.queueMapKey[:0]
-			d.queueMapValue = d.queueMapValue[:0]
-		}
-
 	case reflect.Ptr:
 		if rv.IsNil() {
 			return 0, errors.New(fmt.Sprint("pointer is null : ", rv.Type()))
@@ -0,0 +1,68 @@
+package zeroformatter
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// SetField overwrites the fixed size field at index in serialized data, without deserializing.
+// value must be fixed size (number, bool, time.Time, time.Duration, char.Char, DateTimeOffset),
+// and its size must be as same as the size of the field in data.
+// Please use the same options as serializing.
+func SetField(data []byte, index int, value interface{}, opts ...Option) error {
+	ds := createDeserializer(data, createOptions(opts))
+	start, end, err := ds.fieldRegion(index)
+	if err != nil {
+		return err
+	}
+	return ds.setField(start, end-start, reflect.ValueOf(value))
+}
+
+// Set overwrites the fixed size field which has the name, in the data of view.
+// The type of value must be as same as the field.
+func (v *View[T]) Set(name string, value interface{}) error {
+	i, err := v.fieldIndex(name)
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(value)
+	if !rv.IsValid() || rv.Type() != v.t.Field(i).Type {
+		return fmt.Errorf("value type is different [ %T : %v ]", value, v.t.Field(i).Type)
+	}
+	s, ok := v.opt.fixedSize(rv.Type())
+	if !ok {
+		return fmt.Errorf("this field is not fixed size : %s", name)
+	}
+	// the field must be in its region, because offsets in the header are not checked in NewView
+	start, end, err := v.fieldRegion(i)
+	if err != nil {
+		return err
+	}
+	if end-start < s {
+		return fmt.Errorf("field region is short for %v : %d", rv.Type(), end-start)
+	}
+	return v.setField(start, s, rv)
+}
+
+func (d *deserializer) setField(offset uint32, size uint32, rv reflect.Value) error {
+	if !rv.IsValid() {
+		return fmt.Errorf("value is invalid")
+	}
+	s, ok := d.opt.fixedSize(rv.Type())
+	if !ok {
+		return fmt.Errorf("this type is not fixed size : %v", rv.Type())
+	}
+	if s != size {
+		return fmt.Errorf("field size is different [ %d : %d ]", s, size)
+	}
+	if uint64(offset)+uint64(size) > uint64(len(d.data)) {
+		return fmt.Errorf("data is short for %v at %d", rv.Type(), offset)
+	}
+
+	b, err := serializeValue(rv, d.opt)
+	if err != nil {
+		return err
+	}
+	copy(d.data[offset:offset+size], b)
+	return nil
+}
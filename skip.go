@@ -0,0 +1,220 @@
+package zeroformatter
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// fixedSize returns the size of t if the size of t is always same.
+// The rule is as same as serializer.isFixedSize.
+func (o options) fixedSize(t reflect.Type) (uint32, bool) {
+	if e, ok := findEnum(t); ok {
+		return e.size, true
+	}
+
+	switch t.Kind() {
+	case reflect.Int8, reflect.Uint8, reflect.Bool:
+		return byte1, true
+
+	case reflect.Int16, reflect.Uint16:
+		return byte2, true
+
+	case reflect.Int32:
+		if t == charType {
+			return byte2, true
+		}
+		return byte4, true
+
+	case reflect.Uint32, reflect.Float32:
+		return byte4, true
+
+	case reflect.Int, reflect.Uint:
+		if o.int64 {
+			return byte8, true
+		}
+		return byte4, true
+
+	case reflect.Int64:
+		if t == durationType {
+			return byte4 + byte8, true
+		}
+		return byte8, true
+
+	case reflect.Uint64, reflect.Float64:
+		return byte8, true
+
+	case reflect.Struct:
+		if t == dateTimeOffsetType {
+			return byte4 + byte8 + byte2, true
+		} else if t == dateTimeType {
+			return byte4 + byte8, true
+		}
+	}
+	return 0, false
+}
+
+// readLength reads the length of string, list and map, with checking data size.
+func (d *deserializer) readLength(offset uint32) (int, uint32, error) {
+	if uint64(offset)+uint64(byte4) > uint64(len(d.data)) {
+		return 0, 0, fmt.Errorf("data is short for length at %d", offset)
+	}
+	b, o := d.readSize4(offset)
+	return int(int32(binary.LittleEndian.Uint32(b))), o, nil
+}
+
+// skip returns the offset next to the value of t which starts from offset.
+// Values are not deserialized, only lengths are read.
+func (d *deserializer) skip(t reflect.Type, offset uint32) (uint32, error) {
+	return d.walk(t, offset, "", nil)
+}
+
+// visitFunc is called with each value in walk, before the value is read.
+type visitFunc func(t reflect.Type, offset uint32, path string)
+
+// walk is skip which calls visit with each value and the path from path.
+// Paths are made only if visit is set.
+func (d *deserializer) walk(t reflect.Type, offset uint32, path string, visit visitFunc) (uint32, error) {
+	if visit != nil {
+		visit(t, offset, path)
+	}
+	// sub returns the path of the element
+	sub := func(a ...interface{}) string {
+		if visit == nil {
+			return ""
+		}
+		return fmt.Sprint(append([]interface{}{path}, a...)...)
+	}
+	dataLen := uint64(len(d.data))
+
+	if s, ok := d.opt.fixedSize(t); ok {
+		if uint64(offset)+uint64(s) > dataLen {
+			return 0, fmt.Errorf("data is short for %v at %d", t, offset)
+		}
+		return offset + s, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		l, o, err := d.readLength(offset)
+		if err != nil {
+			return 0, err
+		}
+		if l < 0 || uint64(o)+uint64(l) > dataLen {
+			return 0, fmt.Errorf("string length is wrong at %d : %d", offset, l)
+		}
+		return o + uint32(l), nil
+
+	case reflect.Array, reflect.Slice:
+		if t == rawType {
+			return 0, fmt.Errorf("size of Raw is unknown at %d", offset)
+		}
+		l, o, err := d.readLength(offset)
+		if err != nil {
+			return 0, err
+		}
+		// data is null
+		if l < 0 {
+			return o, nil
+		}
+		if t.Kind() == reflect.Array && l != t.Len() {
+			return 0, fmt.Errorf("array length is different at %d : data[%d] array[%d]", offset, l, t.Len())
+		}
+
+		e := t.Elem()
+		if s, ok := d.opt.fixedSize(e); ok {
+			if uint64(o)+uint64(s)*uint64(l) > dataLen {
+				return 0, fmt.Errorf("list length is wrong at %d : %d", offset, l)
+			}
+			if visit == nil {
+				return o + s*uint32(l), nil
+			}
+		}
+		for i := 0; i < l; i++ {
+			o, err = d.walk(e, o, sub("[", i, "]"), visit)
+			if err != nil {
+				return 0, err
+			}
+		}
+		return o, nil
+
+	case reflect.Struct:
+		if t == unknownType {
+			return offset, nil
+		}
+		o := offset
+		for i := 0; i < t.NumField(); i++ {
+			var err error
+			o, err = d.walk(t.Field(i).Type, o, sub(".", t.Field(i).Name), visit)
+			if err != nil {
+				return 0, err
+			}
+		}
+		return o, nil
+
+	case reflect.Map:
+		l, o, err := d.readLength(offset)
+		if err != nil {
+			return 0, err
+		}
+		if l < 0 {
+			return 0, fmt.Errorf("map length is wrong at %d : %d", offset, l)
+		}
+		for i := 0; i < l; i++ {
+			o, err = d.walk(t.Key(), o, sub("[", i, "][key]"), visit)
+			if err != nil {
+				return 0, err
+			}
+			o, err = d.walk(t.Elem(), o, sub("[", i, "][value]"), visit)
+			if err != nil {
+				return 0, err
+			}
+		}
+		return o, nil
+
+	case reflect.Ptr:
+		return d.walk(t.Elem(), offset, path, visit)
+	}
+	return 0, errors.New(fmt.Sprint("this type is not supported : ", t))
+}
+
+// checkField checks that the field at index in the object of t is in data, without deserializing.
+// Deserializing does not check bounds of each value, so this is used for data which may be corrupted.
+func (d *deserializer) checkField(t reflect.Type, index int) error {
+	ft := t.Field(index).Type
+	// Raw is checked by fieldRegion
+	if ft == rawType {
+		return nil
+	}
+	lastIndex, err := d.readHeader()
+	if err != nil {
+		return err
+	}
+	o := d.fieldOffset(index)
+	if o < (lastIndex+3)*byte4 || o > uint32(len(d.data)) {
+		return fmt.Errorf("field offset is wrong : %d", o)
+	}
+	_, err = d.skip(ft, o)
+	return err
+}
+
+// checkHolder checks that byte data of t is in data, as same as checkField.
+func (d *deserializer) checkHolder(t reflect.Type) error {
+	if isInlineStruct(t) {
+		if _, err := d.checkHeader(t); err != nil {
+			return err
+		}
+		for i := 0; i < numIndex(t); i++ {
+			if err := d.checkField(t, i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if t == rawType {
+		return nil
+	}
+	_, err := d.skip(t, 0)
+	return err
+}
@@ -0,0 +1,130 @@
+package zeroformatter
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Unknown keeps fields whose indexes are not defined in the struct.
+// When a newer application adds fields, an older one which has Unknown as the last field
+// of the top level struct deserializes them into Unknown, and serializing writes them back as they are.
+// So the fields are not lost in relays.
+//
+//	type Message struct {
+//		Name    string
+//		Unknown zeroformatter.Unknown
+//	}
+//
+// Unknown is not counted as an index. It is ignored in nested structs.
+type Unknown struct {
+	fields []Raw
+}
+
+var unknownType = reflect.TypeOf(Unknown{})
+
+// Len returns the number of unknown fields.
+func (u Unknown) Len() int {
+	return len(u.fields)
+}
+
+// Fields returns byte datas of unknown fields in index order. Please do not modify them.
+func (u Unknown) Fields() []Raw {
+	return u.fields
+}
+
+// hasUnknown reports whether the last field of t is Unknown.
+func hasUnknown(t reflect.Type) bool {
+	return t.NumField() > 0 && t.Field(t.NumField()-1).Type == unknownType
+}
+
+// numIndex returns the number of indexes of the object. Unknown is not counted.
+func numIndex(t reflect.Type) int {
+	if hasUnknown(t) {
+		return t.NumField() - 1
+	}
+	return t.NumField()
+}
+
+// unknownOf returns unknown fields of the object rv.
+func unknownOf(rv reflect.Value) []Raw {
+	if !hasUnknown(rv.Type()) {
+		return nil
+	}
+	return rv.Field(rv.NumField() - 1).Interface().(Unknown).fields
+}
+
+// readUnknown sets fields from index n to the last index into the Unknown of rv.
+func (d *deserializer) readUnknown(rv reflect.Value, n int, lastIndex uint32) error {
+	if !hasUnknown(rv.Type()) {
+		return nil
+	}
+
+	u := Unknown{}
+	for i := n; i <= int(lastIndex); i++ {
+		start, end, err := d.fieldRegion(i)
+		if err != nil {
+			return err
+		}
+		u.fields = append(u.fields, append(Raw{}, d.data[start:end]...))
+	}
+	rv.Field(rv.NumField() - 1).Set(reflect.ValueOf(u))
+	return nil
+}
+
+// checkHeader checks size and last index of the object header, and returns the last index.
+// If t has Unknown, the last index can be larger than t has.
+func (d *deserializer) checkHeader(t reflect.Type) (uint32, error) {
+	dataIndex, err := d.readHeader()
+	if err != nil {
+		return 0, err
+	}
+	numField := numIndex(t)
+	if hasUnknown(t) {
+		if int64(dataIndex) < int64(numField-1) {
+			return 0, fmt.Errorf("data index is short [ %d : %d ]", dataIndex, numField-1)
+		}
+	} else if int64(dataIndex) != int64(numField-1) {
+		return 0, fmt.Errorf("data index is diffrent [ %d : %d ]", dataIndex, numField-1)
+	}
+	if d.opt.strict {
+		if err := d.checkRegions(t, dataIndex); err != nil {
+			return 0, err
+		}
+	}
+	return dataIndex, nil
+}
+
+// checkRegions checks that each field starts at the end of the previous field, and the last one ends at end of data.
+// Values are not deserialized, only lengths are read.
+func (d *deserializer) checkRegions(t reflect.Type, lastIndex uint32) error {
+	numField := numIndex(t)
+	end := (3 + lastIndex) * byte4
+
+	// the size of Raw and unknown fields is decided by the next offset
+	open := false
+	for i := 0; i <= int(lastIndex); i++ {
+		o := d.fieldOffset(i)
+		if open {
+			if o < end || o > uint32(len(d.data)) {
+				return fmt.Errorf("offset of index %d is wrong : %d", i, o)
+			}
+		} else if o != end {
+			return fmt.Errorf("offset of index %d is not contiguous [ %d : %d ]", i, o, end)
+		}
+
+		end, open = o, true
+		if i < numField && t.Field(i).Type != rawType {
+			var err error
+			end, err = d.skip(t.Field(i).Type, o)
+			if err != nil {
+				return err
+			}
+			open = false
+		}
+	}
+
+	if !open && end != uint32(len(d.data)) {
+		return fmt.Errorf("%d bytes are not used after the last field", uint32(len(d.data))-end)
+	}
+	return nil
+}
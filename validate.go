@@ -0,0 +1,186 @@
+package zeroformatter
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ValidationProblem is a malformed region of byte data.
+type ValidationProblem struct {
+	// Offset is the position of the problem in byte data.
+	Offset uint32
+	// Path is the value which has the problem, such as "main.Struct.Children[2].Name".
+	Path   string
+	Reason string
+}
+
+func (p ValidationProblem) Error() string {
+	return fmt.Sprintf("%s at %d : %s", p.Path, p.Offset, p.Reason)
+}
+
+// ValidationError has all problems which Validate found.
+type ValidationError struct {
+	Problems []ValidationProblem
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		msgs[i] = p.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Validate checks that byte data is well formed for t, without deserializing to the value of t.
+//
+// The size and the last index in the object header, offsets which must be in data and monotonic,
+// lengths of strings, lists and maps, bool values which must be 0 or 1, values of enum,
+// and overlaps of fields are checked. If t is not an object, bytes after the value are also a problem.
+// Checking is continued from the next field after a problem is found,
+// and all problems are returned as *ValidationError. If data is well formed, nil is returned.
+func Validate(data []byte, t reflect.Type, opts ...Option) error {
+	if t == nil {
+		return fmt.Errorf("type is nil")
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	v := &validator{d: createDeserializer(data, createOptions(opts))}
+	switch {
+	case isInlineStruct(t):
+		v.object(t)
+	case t == rawType:
+		// byte data as it is, as same as Deserialize
+	default:
+		if end, ok := v.value(t, 0, t.String()); ok && end != uint32(len(data)) {
+			v.add(end, t.String(), fmt.Sprintf("%d bytes remain after the value", uint32(len(data))-end))
+		}
+	}
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+type validator struct {
+	d        *deserializer
+	problems []ValidationProblem
+}
+
+func (v *validator) add(offset uint32, path string, reason string) {
+	v.problems = append(v.problems, ValidationProblem{Offset: offset, Path: path, Reason: reason})
+}
+
+func (v *validator) object(t reflect.Type) {
+	data := v.d.data
+	dataLen := uint32(len(data))
+	path := t.String()
+	if dataLen < byte4*2 {
+		v.add(0, path, fmt.Sprintf("data size is not enough for header: %d", dataLen))
+		return
+	}
+
+	if size := binary.LittleEndian.Uint32(data); size != dataLen {
+		v.add(0, path, fmt.Sprintf("data size is wrong [ %d : %d ]", size, dataLen))
+	}
+
+	lastIndex := binary.LittleEndian.Uint32(data[byte4:])
+	numField := numIndex(t)
+	if hasUnknown(t) {
+		if int64(lastIndex) < int64(numField-1) {
+			v.add(byte4, path, fmt.Sprintf("data index is short [ %d : %d ]", lastIndex, numField-1))
+		}
+	} else if int64(lastIndex) != int64(numField-1) {
+		v.add(byte4, path, fmt.Sprintf("data index is diffrent [ %d : %d ]", lastIndex, numField-1))
+	}
+
+	if uint64(dataLen) < (uint64(lastIndex)+3)*uint64(byte4) {
+		v.add(byte4, path, fmt.Sprintf("data size is not enough for header: %d", dataLen))
+		return
+	}
+	headerEnd := (lastIndex + 3) * byte4
+
+	// offsets
+	count := int(lastIndex) + 1
+	offsets := make([]uint32, count)
+	valid := make([]bool, count)
+	prev := headerEnd
+	for i := range offsets {
+		o := v.d.fieldOffset(i)
+		offsets[i] = o
+		p := v.fieldPath(t, i)
+		switch {
+		case o < headerEnd || o > dataLen:
+			v.add(uint32(2+i)*byte4, p, fmt.Sprintf("offset is out of data : %d", o))
+		case o < prev:
+			v.add(uint32(2+i)*byte4, p, fmt.Sprintf("offset is smaller than the previous one : %d < %d", o, prev))
+		default:
+			valid[i] = true
+			prev = o
+		}
+	}
+
+	// values
+	for i := 0; i < numField && i < count; i++ {
+		if !valid[i] || t.Field(i).Type == rawType {
+			continue
+		}
+		p := v.fieldPath(t, i)
+		end, ok := v.value(t.Field(i).Type, offsets[i], p)
+		if !ok {
+			continue
+		}
+		// the next field must start after this field
+		for j := i + 1; j < count; j++ {
+			if !valid[j] {
+				continue
+			}
+			if end > offsets[j] {
+				v.add(offsets[j], p, fmt.Sprintf("field overlaps the index %d : ends at %d", j, end))
+			}
+			break
+		}
+	}
+}
+
+func (v *validator) fieldPath(t reflect.Type, index int) string {
+	if index < numIndex(t) {
+		return t.String() + "." + t.Field(index).Name
+	}
+	return fmt.Sprintf("%s[%d]", t.String(), index)
+}
+
+// value checks the value of t at offset, and returns the offset next to it.
+// false is returned if the size of the value is unknown because of the problem.
+func (v *validator) value(t reflect.Type, offset uint32, path string) (uint32, bool) {
+	// the problem of walk is at the last visited value
+	last, lastPath := offset, path
+	end, err := v.d.walk(t, offset, path, func(t reflect.Type, offset uint32, path string) {
+		last, lastPath = offset, path
+		v.fixedValue(t, offset, path)
+	})
+	if err != nil {
+		v.add(last, lastPath, err.Error())
+		return 0, false
+	}
+	return end, true
+}
+
+// fixedValue checks the value of bool and enum, which have invalid values.
+func (v *validator) fixedValue(t reflect.Type, offset uint32, path string) {
+	s, ok := v.d.opt.fixedSize(t)
+	if !ok || uint64(offset)+uint64(s) > uint64(len(v.d.data)) {
+		return
+	}
+	if e, ok := findEnum(t); ok {
+		if _, err := v.d.deserializeEnum(reflect.New(t).Elem(), e, offset); err != nil {
+			v.add(offset, path, err.Error())
+		}
+	} else if t.Kind() == reflect.Bool && v.d.data[offset] > 1 {
+		v.add(offset, path, fmt.Sprintf("bool must be 0 or 1 : %d", v.d.data[offset]))
+	}
+}
@@ -0,0 +1,88 @@
+package zeroformatter
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// View reads fields of serialized struct T from byte data on demand.
+// T is never allocated, only the field which is read is deserialized.
+// If you need a few fields of large data, please use this.
+type View[T any] struct {
+	*deserializer
+	t reflect.Type
+}
+
+// NewView checks the header of data and creates a view of T.
+func NewView[T any](data []byte, opts ...Option) (*View[T], error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct || t == dateTimeType || t == dateTimeOffsetType {
+		return nil, fmt.Errorf("only defined struct can be viewed: %v", t)
+	}
+
+	ds := createDeserializer(data, createOptions(opts))
+	if _, err := ds.checkHeader(t); err != nil {
+		return nil, err
+	}
+	return &View[T]{deserializer: ds, t: t}, nil
+}
+
+// NumField returns the number of fields, Unknown is not counted.
+func (v *View[T]) NumField() int {
+	return numIndex(v.t)
+}
+
+// Field deserializes the field which has the name and returns it.
+func (v *View[T]) Field(name string) (interface{}, error) {
+	i, err := v.fieldIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	return v.FieldByIndex(i)
+}
+
+// FieldByIndex deserializes the field at index i and returns it.
+func (v *View[T]) FieldByIndex(i int) (interface{}, error) {
+	if i < 0 || i >= numIndex(v.t) {
+		return nil, fmt.Errorf("this index is out of range : %d", i)
+	}
+	if err := v.checkField(v.t, i); err != nil {
+		return nil, err
+	}
+	rv := reflect.New(v.t.Field(i).Type).Elem()
+	if err := v.deserializeAt(rv, i); err != nil {
+		return nil, err
+	}
+	return rv.Interface(), nil
+}
+
+// Decode deserializes the field which has the name and sets into holder.
+// holder must be a pointer of the field type.
+func (v *View[T]) Decode(name string, holder interface{}) error {
+	i, err := v.fieldIndex(name)
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(holder)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("holder must set pointer value. but got: %T", holder)
+	}
+	rv = rv.Elem()
+	if rv.Type() != v.t.Field(i).Type {
+		return fmt.Errorf("holder type is different [ %v : %v ]", rv.Type(), v.t.Field(i).Type)
+	}
+
+	if err := v.checkField(v.t, i); err != nil {
+		return err
+	}
+	return v.deserializeAt(rv, i)
+}
+
+func (v *View[T]) fieldIndex(name string) (int, error) {
+	f, ok := v.t.FieldByName(name)
+	if !ok || len(f.Index) != 1 || f.Index[0] >= numIndex(v.t) {
+		return 0, fmt.Errorf("not found field: %s", name)
+	}
+	return f.Index[0], nil
+}
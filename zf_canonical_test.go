@@ -0,0 +1,87 @@
+package zeroformatter_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/shamaton/zeroformatter"
+	"github.com/shamaton/zeroformatter/char"
+	"github.com/shamaton/zeroformatter/datetimeoffset"
+)
+
+func TestCanonical(t *testing.T) {
+	type key struct {
+		A [2]int16
+		B string
+	}
+	type st struct {
+		Strings map[string]int
+		Floats  map[float64]bool
+		Times   map[time.Time]int8
+		Offsets map[datetimeoffset.DateTimeOffset]int8
+		Chars   map[char.Char][]string
+		Structs map[key]map[int]string
+		Bools   map[bool]uint
+	}
+	now := time.Unix(1500000000, 0)
+	create := func() st {
+		v := st{
+			Strings: map[string]int{},
+			Floats:  map[float64]bool{math.NaN(): true, math.Inf(-1): false, math.Copysign(0, -1): false, 1.5: true},
+			Times:   map[time.Time]int8{},
+			Offsets: map[datetimeoffset.DateTimeOffset]int8{},
+			Chars:   map[char.Char][]string{'a': {"x"}, 'あ': nil, 0xD800: {"y", "z"}},
+			Structs: map[key]map[int]string{},
+			Bools:   map[bool]uint{true: 1, false: 0},
+		}
+		for i := 0; i < 50; i++ {
+			v.Strings[string(rune('A'+i))] = i
+			v.Times[now.Add(time.Duration(i)*time.Second).In(time.FixedZone("", i*60))] = int8(i)
+			v.Offsets[datetimeoffset.UnixOffset(1500000000, 0, i-25)] = int8(i)
+			v.Structs[key{A: [2]int16{int16(i % 3), int16(i)}, B: "b"}] = map[int]string{i: "i", -i - 1: "j"}
+		}
+		return v
+	}
+
+	b, err := zeroformatter.SerializeCanonical(create())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		b2, err := zeroformatter.Serialize(create(), zeroformatter.WithCanonical())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b, b2) {
+			t.Fatal("canonical bytes are different")
+		}
+	}
+
+	r := st{}
+	if err := zeroformatter.Deserialize(&r, b); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Strings) != 50 || len(r.Floats) != 4 || len(r.Structs) != 50 || r.Chars[0xD800][1] != "z" {
+		t.Error("value different", r)
+	}
+
+	// keys are sorted
+	type small struct {
+		Map map[int16]string
+	}
+	b, err = zeroformatter.SerializeCanonical(small{Map: map[int16]string{3: "c", -1: "a", 2: "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := []byte{
+		3, 0, 0, 0,
+		0xff, 0xff, 1, 0, 0, 0, 'a',
+		2, 0, 1, 0, 0, 0, 'b',
+		3, 0, 1, 0, 0, 0, 'c',
+	}
+	if !bytes.Equal(b[12:], e) {
+		t.Error("order different", b[12:])
+	}
+}
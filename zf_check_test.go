@@ -0,0 +1,108 @@
+package zeroformatter_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shamaton/zeroformatter"
+	"github.com/shamaton/zeroformatter/char"
+	"github.com/shamaton/zeroformatter/datetimeoffset"
+)
+
+func TestCheck(t *testing.T) {
+	type node struct {
+		Name     string
+		Children []node
+		Parents  []*node
+	}
+	type supported struct {
+		Int      int
+		Char     char.Char
+		Time     time.Time
+		Offset   datetimeoffset.DateTimeOffset
+		Duration time.Duration
+		Map      map[string][]float32
+		Array    [2]uint16
+		Node     node
+		Raw      zeroformatter.Raw
+		Unknown  zeroformatter.Unknown
+	}
+	err := zeroformatter.Check(reflect.TypeOf(supported{}))
+	var ce *zeroformatter.CheckError
+	if !errors.As(err, &ce) || len(ce.Unsupported()) != 0 || len(ce.Problems) != 1 {
+		t.Fatal("check error", err)
+	}
+	if p := ce.Problems[0]; !p.NilRisk || p.Path != "zeroformatter_test.supported.Node.Parents[]" {
+		t.Error("problem different", p)
+	}
+	if err := zeroformatter.Check(reflect.TypeOf([]string{})); err != nil {
+		t.Error(err)
+	}
+
+	type child struct {
+		Raw zeroformatter.Raw
+	}
+	type list struct {
+		Name string
+		Next *list
+	}
+	type unsupported struct {
+		Func     func()
+		Empty    []chan int
+		Map      map[complex64]interface{}
+		Child    child
+		private  int
+		Unknown  zeroformatter.Unknown
+		Uintptr  uintptr
+		Pointers []*int
+		List     list
+	}
+	err = zeroformatter.Check(reflect.TypeOf(unsupported{}))
+	if !errors.As(err, &ce) {
+		t.Fatal("check error", err)
+	}
+	paths := []string{}
+	for _, p := range ce.Unsupported() {
+		paths = append(paths, p.Path)
+	}
+	e := []string{
+		"zeroformatter_test.unsupported.Func",
+		"zeroformatter_test.unsupported.Empty[]",
+		"zeroformatter_test.unsupported.Map[key]",
+		"zeroformatter_test.unsupported.Map[value]",
+		"zeroformatter_test.unsupported.Child.Raw",
+		"zeroformatter_test.unsupported.private",
+		"zeroformatter_test.unsupported.Unknown",
+		"zeroformatter_test.unsupported.Uintptr",
+		"zeroformatter_test.unsupported.List.Next",
+	}
+	if !reflect.DeepEqual(paths, e) {
+		t.Error("paths different", paths)
+	}
+	if len(ce.Problems) != len(e)+1 || !ce.Problems[len(e)-1].NilRisk || ce.Problems[len(e)-1].Path != "zeroformatter_test.unsupported.Pointers[]" {
+		t.Error("nil risk different", ce.Problems)
+	}
+
+	// recursive pointer always ends with nil
+	if _, err := zeroformatter.NewCodec[list](); err == nil {
+		t.Error("recursive pointer error")
+	}
+}
+
+func TestMustRegister(t *testing.T) {
+	type st struct {
+		Pointer *int
+	}
+	if c := zeroformatter.MustRegister[st](); c == nil {
+		t.Error("codec is nil")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("not panic")
+		}
+	}()
+	zeroformatter.MustRegister[chan int]()
+}
@@ -0,0 +1,148 @@
+package zeroformatter_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shamaton/zeroformatter"
+	"github.com/shamaton/zeroformatter/char"
+)
+
+func TestMarshal(t *testing.T) {
+	type st struct {
+		Int    int
+		String string
+		Time   time.Time
+	}
+	vSt := st{Int: 1, String: "generic", Time: now}
+	b, err := zeroformatter.Marshal(vSt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rSt, err := zeroformatter.Unmarshal[st](b)
+	if err != nil || !reflect.DeepEqual(rSt, vSt) {
+		t.Error("value different", rSt, err)
+	}
+
+	// pointer
+	b, err = zeroformatter.Marshal(&vSt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prSt, err := zeroformatter.Unmarshal[*st](b)
+	if err != nil || !reflect.DeepEqual(*prSt, vSt) {
+		t.Error("value different", prSt, err)
+	}
+	if _, err := zeroformatter.Marshal[*st](nil); err == nil {
+		t.Error("nil error")
+	}
+
+	// primitive
+	b, err = zeroformatter.Marshal([]string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, err := zeroformatter.Unmarshal[[]string](b); err != nil || !reflect.DeepEqual(r, []string{"a", "b"}) {
+		t.Error("value different", r, err)
+	}
+}
+
+func TestCodec(t *testing.T) {
+	type fixed struct {
+		Int      int32
+		Char     char.Char
+		Time     time.Time
+		Duration time.Duration
+	}
+	type variable struct {
+		Name   string
+		Fixed  fixed
+		Fixeds []fixed
+	}
+
+	fc, err := zeroformatter.NewCodec[fixed]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vFixed := fixed{Int: -1, Char: 'c', Time: now, Duration: time.Hour}
+	b, err := fc.Marshal(vFixed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, _ := zeroformatter.Serialize(vFixed); !reflect.DeepEqual(b, e) {
+		t.Error("data different", b, e)
+	}
+	if r, err := fc.Unmarshal(b); err != nil || !reflect.DeepEqual(r, vFixed) {
+		t.Error("value different", r, err)
+	}
+	if _, err := fc.Unmarshal(b[:len(b)-1]); err == nil {
+		t.Error("size error")
+	}
+
+	vc, err := zeroformatter.NewCodec[*variable](zeroformatter.WithLocation(time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vVariable := &variable{Name: "v", Fixed: vFixed, Fixeds: []fixed{vFixed, vFixed}}
+	b, err = vc.Marshal(vVariable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, _ := zeroformatter.Serialize(vVariable); !reflect.DeepEqual(b, e) {
+		t.Error("data different", b, e)
+	}
+	var r *variable
+	if err := vc.UnmarshalTo(b, &r); err != nil || r.Name != "v" || len(r.Fixeds) != 2 || r.Fixed.Time.Location() != time.UTC {
+		t.Error("value different", r, err)
+	}
+
+	// not supported
+	type child struct {
+		Func func()
+	}
+	type unsupported struct {
+		Int      int
+		Children []child
+	}
+	_, err = zeroformatter.NewCodec[unsupported]()
+	if err == nil || !strings.Contains(err.Error(), "unsupported.Children[].Func") {
+		t.Error("type error", err)
+	}
+	if _, err := zeroformatter.NewCodec[map[string]interface{}](); err == nil {
+		t.Error("type error")
+	}
+	if _, err := zeroformatter.NewCodec[struct{ c complex128 }](); err == nil {
+		t.Error("type error")
+	}
+}
+
+type priority uint8
+
+func TestCodecEnumRegisteredLater(t *testing.T) {
+	type task struct {
+		ID       int32
+		Priority priority
+	}
+	// as same as the codec in a package variable, which is created before init
+	c, err := zeroformatter.NewCodec[task]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := zeroformatter.RegisterEnum(reflect.TypeOf(priority(0)), reflect.Int64); err != nil {
+		t.Fatal(err)
+	}
+
+	v := task{ID: 1, Priority: 3}
+	b, err := c.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, _ := zeroformatter.Serialize(v); !reflect.DeepEqual(b, e) {
+		t.Error("data different", b, e)
+	}
+	if r, err := c.Unmarshal(b); err != nil || r != v {
+		t.Error("value different", r, err)
+	}
+}
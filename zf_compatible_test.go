@@ -0,0 +1,111 @@
+package zeroformatter_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/shamaton/zeroformatter"
+)
+
+type compatColor int16
+
+func TestCompatible(t *testing.T) {
+	if err := zeroformatter.RegisterEnum(reflect.TypeOf(compatColor(0)), reflect.Int32); err != nil {
+		t.Fatal(err)
+	}
+
+	type child struct {
+		A int
+		B string
+	}
+	type v1 struct {
+		ID    int32
+		Name  string
+		Color int
+		Child child
+		Tags  map[string][]int64
+	}
+	// renamed and same wire types
+	type v2 struct {
+		Key   int
+		Title string
+		Color compatColor
+		Child struct {
+			X int32
+			Y string
+		}
+		Labels map[string][]int64
+	}
+	if err := zeroformatter.Compatible(reflect.TypeOf(v1{}), reflect.TypeOf(v2{})); err != nil {
+		t.Error(err)
+	}
+
+	type v3 struct {
+		ID    int64
+		Name  []byte
+		Color int
+		Child struct {
+			A int
+		}
+		Tags map[string][]int32
+	}
+	paths := compatibleProblems(t, reflect.TypeOf(v1{}), reflect.TypeOf(v3{}))
+	e := []string{
+		"zeroformatter_test.v1.ID",
+		"zeroformatter_test.v1.Name",
+		"zeroformatter_test.v1.Child",
+		"zeroformatter_test.v1.Tags[value][]",
+	}
+	if !reflect.DeepEqual(paths, e) {
+		t.Error("problems different", paths)
+	}
+
+	// added index
+	type v4 struct {
+		ID      int32
+		Name    string
+		Color   int
+		Child   child
+		Tags    map[string][]int64
+		Added   bool
+		Unknown zeroformatter.Unknown
+	}
+	if p := compatibleProblems(t, reflect.TypeOf(v1{}), reflect.TypeOf(v4{})); len(p) != 2 {
+		t.Error("problems different", p)
+	}
+	// removed index
+	if p := compatibleProblems(t, reflect.TypeOf(v4{}), reflect.TypeOf(v1{})); len(p) != 2 {
+		t.Error("problems different", p)
+	}
+
+	// raw is compatible
+	type raw struct {
+		ID    zeroformatter.Raw
+		Name  string
+		Color int
+		Child zeroformatter.Raw
+		Tags  map[string][]int64
+	}
+	if err := zeroformatter.Compatible(reflect.TypeOf(v1{}), reflect.TypeOf(raw{})); err != nil {
+		t.Error(err)
+	}
+
+	// not object
+	if err := zeroformatter.Compatible(reflect.TypeOf([]int{}), reflect.TypeOf([]uint{})); err == nil {
+		t.Error("type change must be error")
+	}
+}
+
+func compatibleProblems(t *testing.T, old, new reflect.Type) []string {
+	t.Helper()
+	var ce *zeroformatter.CheckError
+	if err := zeroformatter.Compatible(old, new); !errors.As(err, &ce) {
+		t.Fatal("check error", err)
+	}
+	paths := []string{}
+	for _, p := range ce.Problems {
+		paths = append(paths, p.Path)
+	}
+	return paths
+}
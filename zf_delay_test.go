@@ -1,6 +1,7 @@
 package zeroformatter_test
 
 import (
+	"sync"
 	"testing"
 	"time"
 
@@ -171,3 +172,324 @@ func TestDelayDeserialize(t *testing.T) {
 		t.Error("value not equal!!")
 	}
 }
+
+func TestReserialize(t *testing.T) {
+	type child struct {
+		Ints  []int
+		Times map[string]time.Time
+	}
+	type st struct {
+		Counter int64
+		String  string
+		Child   child
+		Chars   []char.Char
+	}
+	vSt := st{
+		Counter: 1,
+		String:  "untouched",
+		Child:   child{Ints: []int{1, 2, 3}, Times: map[string]time.Time{"now": now}},
+		Chars:   char.FromString("abc"),
+	}
+	b, err := zeroformatter.Serialize(vSt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing changed
+	holder := &st{}
+	dds, err := zeroformatter.DelayDeserialize(holder, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := dds.Reserialize()
+	if err != nil || !reflect.DeepEqual(r, b) {
+		t.Error("data different", err)
+	}
+
+	// change a few fields
+	if err := dds.DeserializeByElement(&holder.Counter, &holder.Chars); err != nil {
+		t.Fatal(err)
+	}
+	holder.Counter++
+	holder.Chars = append(holder.Chars, 'd')
+	r, err = dds.Reserialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	eSt := vSt
+	eSt.Counter++
+	eSt.Chars = char.FromString("abcd")
+	e, err := zeroformatter.Serialize(eSt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r, e) {
+		t.Error("data different", r, e)
+	}
+
+	rSt := st{}
+	if err := zeroformatter.Deserialize(&rSt, r); err != nil || !reflect.DeepEqual(rSt, eSt) {
+		t.Error("value different", rSt, err)
+	}
+
+	// change without deserializing
+	holder.String = "lost"
+	if _, err := dds.Reserialize(); err == nil {
+		t.Error("error should occur")
+	}
+	holder.String = ""
+	if err := dds.DeserializeByPath("Child.Ints"); err != nil {
+		t.Fatal(err)
+	}
+	holder.Child.Times = map[string]time.Time{}
+	if _, err := dds.Reserialize(); err == nil {
+		t.Error("error should occur")
+	}
+	holder.Child.Times = nil
+
+	// set without deserializing
+	if err := dds.Set("String", "set"); err != nil {
+		t.Fatal(err)
+	}
+	r, err = dds.Reserialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	eSt.String = "set"
+	rSt = st{}
+	if err := zeroformatter.Deserialize(&rSt, r); err != nil || !reflect.DeepEqual(rSt, eSt) {
+		t.Error("value different", rSt, err)
+	}
+	if err := dds.Set("None", 1); err == nil {
+		t.Error("error should occur")
+	}
+	if err := dds.Set("String", 1); err == nil {
+		t.Error("error should occur")
+	}
+}
+
+func TestDelayedDecoder(t *testing.T) {
+	type st struct {
+		Int    int
+		String string
+		Ints   []int16
+	}
+	b1, err := zeroformatter.Serialize(st{Int: 1, String: "first", Ints: []int16{1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b2, err := zeroformatter.Serialize(st{Int: 2, String: "second", Ints: []int16{2, 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	holder := &st{}
+	var dds *zeroformatter.DelayedDecoder
+	dds, err = zeroformatter.DelayDeserialize(holder, b1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dds.Remaining() != 3 {
+		t.Error("remaining different", dds.Remaining())
+	}
+	if err := dds.DeserializeByIndex(1); err != nil {
+		t.Error(err)
+	}
+	if dds.Remaining() != 2 {
+		t.Error("remaining different", dds.Remaining())
+	}
+	if err := dds.DeserializeAll(); err != nil {
+		t.Error(err)
+	}
+	if dds.Remaining() != 0 || !reflect.DeepEqual(*holder, st{Int: 1, String: "first", Ints: []int16{1}}) {
+		t.Error("value different", holder)
+	}
+
+	// reuse
+	if err := dds.Reset(b2); err != nil {
+		t.Fatal(err)
+	}
+	if dds.Remaining() != 3 || !reflect.DeepEqual(*holder, st{}) {
+		t.Error("reset error", holder)
+	}
+	if err := dds.DeserializeByElement(&holder.Ints); err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(*holder, st{Ints: []int16{2, 2}}) {
+		t.Error("value different", holder)
+	}
+	if err := dds.Reset(b2[:len(b2)-1]); err == nil {
+		t.Error("size error")
+	}
+	if err := dds.DeserializeByIndex(-1); err == nil {
+		t.Error("index error")
+	}
+
+	// broken data is not marked as deserialized
+	broken := append([]byte{}, b1...)
+	broken[len(broken)-6] = 0xff
+	if err := dds.Reset(broken); err != nil {
+		t.Fatal(err)
+	}
+	if err := dds.DeserializeByElement(&holder.Ints); err == nil {
+		t.Error("data error")
+	}
+	if ok, err := dds.IsDeserialized(&holder.Ints); err != nil || ok {
+		t.Error("deserialized error")
+	}
+}
+
+func TestDelayedDecoderConcurrency(t *testing.T) {
+	type st struct {
+		Int    int
+		String string
+		Ints   []int16
+		Map    map[string]float64
+		Time   time.Time
+	}
+	vSt := st{Int: 1, String: "shared", Ints: []int16{1, 2, 3}, Map: map[string]float64{"a": 1.5}, Time: now}
+	b, err := zeroformatter.Serialize(vSt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for n := 0; n < 20; n++ {
+		holder := &st{}
+		dds, err := zeroformatter.DelayDeserialize(holder, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 100)
+		for g := 0; g < 100; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				var err error
+				switch g % 4 {
+				case 0:
+					err = dds.DeserializeByElement(&holder.String, &holder.Map)
+				case 1:
+					err = dds.DeserializeByIndex(2, 0)
+				case 2:
+					_, err = dds.IsDeserialized(&holder.Time)
+					dds.Remaining()
+				default:
+					err = dds.DeserializeAll()
+				}
+				if err != nil {
+					errs <- err
+				}
+			}(g)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Error(err)
+		}
+
+		if dds.Remaining() != 0 || !reflect.DeepEqual(vSt, *holder) {
+			t.Error("value different", holder)
+		}
+	}
+}
+
+func TestDelayDeserializeNested(t *testing.T) {
+	type child2 struct {
+		Strings  []string
+		Int2Uint map[int]uint
+	}
+	type child struct {
+		Time  time.Time
+		Child child2
+		Int   int
+	}
+	type st struct {
+		Int   int
+		Child child
+	}
+	vSt := st{
+		Int: 1,
+		Child: child{
+			Time:  now,
+			Child: child2{Strings: []string{"a", "b"}, Int2Uint: map[int]uint{-1: 1}},
+			Int:   2,
+		},
+	}
+	b, err := zeroformatter.Serialize(vSt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	holder := &st{}
+	dds, err := zeroformatter.DelayDeserialize(holder, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// by element
+	if err := dds.DeserializeByElement(&holder.Child.Child.Int2Uint); err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(holder.Child.Child.Int2Uint, vSt.Child.Child.Int2Uint) || holder.Child.Child.Strings != nil || holder.Child.Int != 0 {
+		t.Error("value different", holder)
+	}
+	if ok, err := dds.IsDeserialized(&holder.Child.Child.Int2Uint); err != nil || !ok {
+		t.Error("deserialized error", err)
+	}
+	if ok, err := dds.IsDeserialized(&holder.Child.Child); err != nil || ok {
+		t.Error("deserialized error", err)
+	}
+
+	// by path
+	if err := dds.DeserializeByPath("Child.Int", "Child.Time"); err != nil {
+		t.Error(err)
+	}
+	if holder.Child.Int != 2 || !holder.Child.Time.Equal(now) || dds.Remaining() != 2 {
+		t.Error("value different", holder)
+	}
+
+	// the rest of the field keeps changed values
+	holder.Child.Int = 3
+	if err := dds.DeserializeByElement(&holder.Child); err != nil {
+		t.Error(err)
+	}
+	if holder.Child.Int != 3 || !reflect.DeepEqual(holder.Child.Child, vSt.Child.Child) || dds.Remaining() != 1 {
+		t.Error("value different", holder)
+	}
+
+	// reserialize changed nested field only
+	holder2 := &st{}
+	dds2, err := zeroformatter.DelayDeserialize(holder2, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dds2.DeserializeByPath("Child.Child.Strings"); err != nil {
+		t.Error(err)
+	}
+	holder2.Child.Child.Strings = append(holder2.Child.Child.Strings, "c")
+	r, err := dds2.Reserialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	eSt := vSt
+	eSt.Child.Child.Strings = []string{"a", "b", "c"}
+	rSt := st{}
+	if err := zeroformatter.Deserialize(&rSt, r); err != nil || !reflect.DeepEqual(rSt, eSt) {
+		t.Error("value different", rSt, err)
+	}
+
+	// error
+	if err := dds.DeserializeByPath("Child.Nothing"); err == nil {
+		t.Error("path error")
+	}
+	if err := dds.DeserializeByPath("Child.Time.Nothing"); err == nil {
+		t.Error("path error")
+	}
+	other := 0
+	if err := dds.DeserializeByElement(&other); err == nil {
+		t.Error("address error")
+	}
+}
@@ -0,0 +1,97 @@
+package zeroformatter_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shamaton/zeroformatter"
+	"github.com/shamaton/zeroformatter/char"
+	"github.com/shamaton/zeroformatter/datetimeoffset"
+)
+
+func TestDescribe(t *testing.T) {
+	type child struct {
+		Name     string
+		Children []child
+	}
+	type st struct {
+		Int      int
+		Char     char.Char
+		Offset   datetimeoffset.DateTimeOffset
+		Duration time.Duration
+		Fixed    []int16
+		Variable [2]string
+		Map      map[string]time.Time
+		Child    child
+		Unknown  zeroformatter.Unknown
+	}
+
+	l, err := zeroformatter.Describe(reflect.TypeOf(&st{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.ZFType != "Object" || l.Fixed || !l.Unknown || len(l.Fields) != 8 {
+		t.Fatal("object layout different", l)
+	}
+
+	type e struct {
+		name, zf string
+		fixed    bool
+		size     uint32
+	}
+	es := []e{
+		{"Int", "Int32", true, 4},
+		{"Char", "Char", true, 2},
+		{"Offset", "DateTimeOffset", true, 14},
+		{"Duration", "TimeSpan", true, 12},
+		{"Fixed", "FixedSizeList", false, 0},
+		{"Variable", "VariableSizeList", false, 0},
+		{"Map", "Dictionary", false, 0},
+		{"Child", "Struct", false, 0},
+	}
+	for i, f := range l.Fields {
+		if f.Index != i || f.Name != es[i].name || f.ZFType != es[i].zf || f.Fixed != es[i].fixed || f.Size != es[i].size {
+			t.Error("field layout different", i, f)
+		}
+	}
+	if m := l.Fields[6]; m.Key.ZFType != "String" || m.Elem.ZFType != "DateTime" || m.Elem.Size != 12 {
+		t.Error("dictionary layout different", m.Key, m.Elem)
+	}
+	if c := l.Fields[7]; len(c.Fields) != 2 || c.Fields[0].Index != -1 || !c.Fields[1].Elem.Recursive {
+		t.Error("struct layout different", c.Fields)
+	}
+
+	head := "zeroformatter_test.st Object variable +unknown\n"
+	s := l.String()
+	if len(s) < len(head) || s[:len(head)] != head {
+		t.Error("string different", s)
+	}
+
+	// fixed object and option
+	type fixed struct {
+		A int
+		B uint8
+	}
+	l, err = zeroformatter.Describe(reflect.TypeOf(fixed{}), zeroformatter.WithInt64())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !l.Fixed || l.Size != 4*4+8+1 || l.Fields[0].ZFType != "Int64" {
+		t.Error("fixed layout different", l)
+	}
+	b, _ := zeroformatter.Serialize(fixed{}, zeroformatter.WithInt64())
+	if uint32(len(b)) != l.Size {
+		t.Error("size is not as same as serialized", len(b), l.Size)
+	}
+
+	// not struct
+	l, err = zeroformatter.Describe(reflect.TypeOf([]int8{}))
+	if err != nil || l.ZFType != "FixedSizeList" || l.Elem.ZFType != "SByte" || l.Index != -1 {
+		t.Error("list layout different", l, err)
+	}
+
+	if _, err = zeroformatter.Describe(reflect.TypeOf(struct{ F func() }{})); err == nil {
+		t.Error("unsupported type must be error")
+	}
+}
@@ -0,0 +1,94 @@
+package zeroformatter_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shamaton/zeroformatter"
+)
+
+func TestDump(t *testing.T) {
+	type child struct {
+		Flag bool
+		Tags []string
+	}
+	type st struct {
+		ID    int32
+		Name  string
+		Child child
+		Map   map[string]int16
+	}
+	v := st{ID: 1, Name: "zf", Child: child{Flag: true, Tags: []string{"a", "b"}}, Map: map[string]int16{"k": 3}}
+	b, err := zeroformatter.Serialize(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := &bytes.Buffer{}
+	if err := zeroformatter.Dump(w, b, reflect.TypeOf(v)); err != nil {
+		t.Fatal(err, w)
+	}
+	e := []string{
+		"00000000  size      : 60",
+		"00000004  lastIndex : 3",
+		"00000008  offset[0] : 24",
+		"0000000c  offset[1] : 28",
+		"00000010  offset[2] : 34",
+		"00000014  offset[3] : 49",
+		"00000018  [0] ID int32 : 1",
+		"0000001c  [1] Name string len=2 : \"zf\"",
+		"00000022  [2] Child zeroformatter_test.child",
+		"00000022    Flag bool : true",
+		"00000023    Tags []string len=2",
+		"00000027      [0] string len=1 : \"a\"",
+		"0000002c      [1] string len=1 : \"b\"",
+		"00000031  [3] Map map[string]int16 len=1",
+		"00000035    [0] key string len=1 : \"k\"",
+		"0000003a    [0] value int16 : 3",
+	}
+	if lines := strings.Split(strings.TrimSpace(w.String()), "\n"); !reflect.DeepEqual(lines, e) {
+		t.Error("dump different", w)
+	}
+
+	// without type
+	w.Reset()
+	if err := zeroformatter.Dump(w, b, nil); err != nil {
+		t.Fatal(err, w)
+	}
+	if !strings.Contains(w.String(), "00000018  [0] bytes=4\n00000018    01 00 00 00\n") {
+		t.Error("raw dump different", w)
+	}
+
+	// malformed regions
+	m := append([]byte{}, b...)
+	binary.LittleEndian.PutUint32(m[12:], 1000) // offset of Name
+	binary.LittleEndian.PutUint32(m[35:], 7)    // length of Tags
+	m[34] = 2                                   // Flag
+	w.Reset()
+	err = zeroformatter.Dump(w, m, reflect.TypeOf(v))
+	if err == nil || err.Error() != "4 malformed regions are found" {
+		t.Error("error different", err, w)
+	}
+	for _, s := range []string{
+		"0000000c  !! offset[1] is out of data : 1000",
+		"0000001c    !! 6 bytes are not read",
+		"00000022    !! Flag bool : bool is not 0 or 1 : 2",
+		"00000031      !! [2] string : string length is wrong : 0",
+		"00000031  [3] Map",
+	} {
+		if !strings.Contains(w.String(), s) {
+			t.Error("dump does not have", s, w)
+		}
+	}
+
+	// short data does not panic
+	for i := 0; i < len(b); i++ {
+		w.Reset()
+		if err := zeroformatter.Dump(w, b[:i], reflect.TypeOf(v)); err == nil {
+			t.Error("short data must be error", i)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package zeroformatter_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/shamaton/zeroformatter"
+)
+
+type color byte
+
+const (
+	red color = iota
+	green
+	blue
+)
+
+type level int64
+
+type signal uint16
+
+func TestEnum(t *testing.T) {
+	if err := zeroformatter.RegisterEnum(reflect.TypeOf(color(0)), reflect.Int32, red, green, blue); err != nil {
+		t.Fatal(err)
+	}
+	if err := zeroformatter.RegisterEnum(reflect.TypeOf(level(0)), reflect.Int8); err != nil {
+		t.Fatal(err)
+	}
+	if err := zeroformatter.RegisterEnum(reflect.TypeOf(signal(0)), reflect.Int16); err != nil {
+		t.Fatal(err)
+	}
+
+	// width
+	d, err := zeroformatter.Serialize(blue)
+	if err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(d, []byte{2, 0, 0, 0}) {
+		t.Error("enum width different", d)
+	}
+
+	type st struct {
+		Color  color
+		Colors []color
+		Level  level
+		Signal signal
+		ByKey  map[color]level
+	}
+	vSt := st{Color: green, Colors: []color{blue, red}, Level: -128, Signal: math.MaxInt16, ByKey: map[color]level{red: 1, blue: -1}}
+	rSt := st{}
+	if err := checkRoutine(t, vSt, &rSt, false); err != nil {
+		t.Error(err)
+	}
+
+	// out of range
+	if _, err := zeroformatter.Serialize(level(128)); err == nil {
+		t.Error("range error")
+	}
+	if _, err := zeroformatter.Serialize(signal(math.MaxInt16 + 1)); err == nil {
+		t.Error("range error")
+	}
+
+	// not defined
+	d, err = zeroformatter.Serialize(int32(3))
+	if err != nil {
+		t.Error(err)
+	}
+	rColor := green
+	if err := zeroformatter.Deserialize(&rColor, d); err == nil || rColor != green {
+		t.Error("value error", rColor, err)
+	}
+	d, err = zeroformatter.Serialize(int16(-1))
+	if err != nil {
+		t.Error(err)
+	}
+	var rSignal signal
+	if err := zeroformatter.Deserialize(&rSignal, d); err == nil {
+		t.Error("range error")
+	}
+
+	// not enum
+	if err := zeroformatter.RegisterEnum(reflect.TypeOf(0), reflect.Int32); err == nil {
+		t.Error("type error")
+	}
+	if err := zeroformatter.RegisterEnum(reflect.TypeOf(color(0)), reflect.Int); err == nil {
+		t.Error("kind error")
+	}
+	if err := zeroformatter.RegisterEnum(reflect.TypeOf(color(0)), reflect.Int32, 1); err == nil {
+		t.Error("value error")
+	}
+}
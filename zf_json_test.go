@@ -0,0 +1,152 @@
+package zeroformatter_test
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shamaton/zeroformatter"
+	"github.com/shamaton/zeroformatter/char"
+	"github.com/shamaton/zeroformatter/datetimeoffset"
+)
+
+func TestJSON(t *testing.T) {
+	type child struct {
+		Initial char.Char
+		Half    char.Char
+	}
+	type st struct {
+		Int      int64
+		Uint     uint64
+		Float32  float32
+		Float64  float64
+		NaN      float64
+		Inf      float32
+		Text     string
+		Time     time.Time
+		Offset   datetimeoffset.DateTimeOffset
+		Duration time.Duration
+		List     []int16
+		Nil      []string
+		Array    [2]bool
+		Strings  map[string]uint8
+		Ints     map[int32]string
+		Child    child
+		Raw      zeroformatter.Raw
+	}
+	raw, _ := zeroformatter.Serialize("raw")
+	v := st{
+		Int:      math.MinInt64,
+		Uint:     math.MaxUint64,
+		Float32:  0.1,
+		Float64:  1.0 / 3,
+		NaN:      math.NaN(),
+		Inf:      float32(math.Inf(-1)),
+		Text:     "<zf>\n",
+		Time:     time.Unix(1500000000, 1).UTC(),
+		Offset:   datetimeoffset.UnixOffset(1500000000, 999999999, 9*60),
+		Duration: 62*time.Minute + 3500*time.Millisecond,
+		List:     []int16{-1, 2},
+		Array:    [2]bool{true, false},
+		Strings:  map[string]uint8{"b": 2, "a": 1},
+		Ints:     map[int32]string{10: "ten", -1: "minus"},
+		Child:    child{Initial: 'あ', Half: 0xD800},
+		Raw:      raw,
+	}
+	b, err := zeroformatter.Serialize(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := zeroformatter.ToJSON(b, reflect.TypeOf(v))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := `{"Int":-9223372036854775808,"Uint":18446744073709551615,"Float32":0.1,"Float64":0.3333333333333333,` +
+		`"NaN":"NaN","Inf":"-Infinity","Text":"<zf>\n","Time":"2017-07-14T02:40:00.000000001Z",` +
+		`"Offset":"2017-07-14T11:40:00.999999999+09:00","Duration":"1h2m3.5s","List":[-1,2],"Nil":[],` +
+		`"Array":[true,false],"Strings":{"a":1,"b":2},"Ints":[[-1,"minus"],[10,"ten"]],` +
+		`"Child":{"Initial":"あ","Half":55296},"Raw":"AwAAAHJhdw=="}`
+	if string(j) != e {
+		t.Errorf("json different\n%s\n%s", j, e)
+	}
+
+	// round trip
+	b2, err := zeroformatter.FromJSON(j, reflect.TypeOf(&v))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// order of map entries in byte data is not fixed
+	if len(b2) != len(b) {
+		t.Error("size different", len(b2), len(b))
+	}
+	if j2, err := zeroformatter.ToJSON(b2, reflect.TypeOf(v)); err != nil || !bytes.Equal(j2, j) {
+		t.Errorf("round trip different\n%s\n%s", j2, j)
+	}
+
+	errs := []string{
+		`{"Int":1.5}`,
+		`{"Uint":-1}`,
+		`{"Float32":"inf"}`,
+		`{"Time":"2017-07-14"}`,
+		`{"Duration":100}`,
+		`{"Array":[true]}`,
+		`{"Ints":{"1":"one"}}`,
+		`{"Ints":[[1]]}`,
+		`{"Child":{"Initial":"ab"}}`,
+		`{"Child":{"Initial":"😀"}}`,
+		`{"Child":{"Half":65536}}`,
+		`{"Raw":"!"}`,
+		`{"Undefined":1}`,
+		`{} {}`,
+	}
+	for _, s := range errs {
+		if _, err := zeroformatter.FromJSON([]byte(s), reflect.TypeOf(v)); err == nil {
+			t.Error("must be error", s)
+		}
+	}
+
+	// negative durations
+	for _, d := range []time.Duration{-1, -time.Second - 1, math.MinInt64, math.MaxInt64} {
+		b, err := zeroformatter.Serialize(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		j, err := zeroformatter.ToJSON(b, reflect.TypeOf(d))
+		if err != nil || string(j) != `"`+d.String()+`"` {
+			t.Error("duration different", string(j), d, err)
+		}
+		if b2, err := zeroformatter.FromJSON(j, reflect.TypeOf(d)); err != nil || !bytes.Equal(b2, b) {
+			t.Error("duration round trip different", d, err)
+		}
+	}
+
+	// invalid UTF-8
+	bs, _ := zeroformatter.Serialize("\xff")
+	if _, err := zeroformatter.ToJSON(bs, reflect.TypeOf("")); err == nil {
+		t.Error("UTF-8 must be error")
+	}
+
+	// corrupted offset
+	broken := append([]byte{}, b...)
+	broken[8] = 0xff
+	if _, err := zeroformatter.ToJSON(broken, reflect.TypeOf(v)); err == nil {
+		t.Error("offset must be error")
+	}
+
+	// schema type uses names in the schema
+	s, err := zeroformatter.ParseSchema("object M { 0: Int32 ID\n 1: Dictionary<Int16, Char> Initials }")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mt, _ := s.Type("M")
+	b, err = zeroformatter.FromJSON([]byte(`{"ID":7,"Initials":[[1,"a"]]}`), mt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j, err = zeroformatter.ToJSON(b, mt); err != nil || string(j) != `{"ID":7,"Initials":[[1,"a"]]}` {
+		t.Error("schema json different", string(j), err)
+	}
+}
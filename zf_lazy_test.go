@@ -0,0 +1,183 @@
+package zeroformatter_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shamaton/zeroformatter"
+)
+
+func TestLazyList(t *testing.T) {
+	// fixed size
+	vInts := []int32{1, -2, 3, -4}
+	b, err := zeroformatter.Serialize(vInts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ints, err := zeroformatter.NewLazyList[int32](b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ints.Len() != len(vInts) {
+		t.Error("length different", ints.Len())
+	}
+	for _, i := range []int{3, 0, 2} {
+		if v, err := ints.Get(i); err != nil || v != vInts[i] {
+			t.Error("value different", i, v, err)
+		}
+	}
+	if _, err := ints.Get(4); err == nil {
+		t.Error("index error")
+	}
+
+	// variable size
+	type item struct {
+		Name string
+		Tags []string
+	}
+	type st struct {
+		Time  time.Time
+		Items []item
+		Times [3]time.Time
+	}
+	vSt := st{
+		Time:  now,
+		Items: []item{{"a", nil}, {"bb", []string{"x", "yy"}}, {"ccc", []string{"z"}}},
+		Times: [3]time.Time{now, now.Add(time.Hour), now.Add(2 * time.Hour)},
+	}
+	b, err = zeroformatter.Serialize(vSt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := zeroformatter.NewView[st](b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := zeroformatter.ListField[item](v, "Items")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items.Len() != 3 {
+		t.Error("length different", items.Len())
+	}
+	for _, i := range []int{2, 1} {
+		r, err := items.Get(i)
+		if err != nil || !reflect.DeepEqual(r, vSt.Items[i]) {
+			t.Error("value different", i, r, err)
+		}
+	}
+	times, err := zeroformatter.ListField[time.Time](v, "Times")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, err := times.Get(1); err != nil || !r.Equal(vSt.Times[1]) {
+		t.Error("value different", r, err)
+	}
+
+	// error
+	if _, err := zeroformatter.ListField[string](v, "Items"); err == nil {
+		t.Error("type error")
+	}
+	if _, err := zeroformatter.ListField[item](v, "Time"); err == nil {
+		t.Error("type error")
+	}
+	if _, err := zeroformatter.NewLazyList[int64]([]byte{2, 0, 0, 0, 1}); err == nil {
+		t.Error("length error")
+	}
+}
+
+func TestLazyMap(t *testing.T) {
+	type key struct {
+		ID   int
+		Name string
+	}
+	type st struct {
+		Int    int
+		Config map[string][]string
+		ByKey  map[key]time.Duration
+		ByTime map[time.Time]int16
+	}
+	vSt := st{
+		Int:    1,
+		Config: map[string][]string{},
+		ByKey:  map[key]time.Duration{{1, "a"}: time.Second, {2, "b"}: time.Minute},
+		ByTime: map[time.Time]int16{now: 1, now.Add(time.Hour): 2},
+	}
+	for i := 0; i < 1000; i++ {
+		vSt.Config[string(rune('a'+i%26))+string(rune('0'+i))] = []string{"v", string(rune(i))}
+	}
+	b, err := zeroformatter.Serialize(vSt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := zeroformatter.NewView[st](b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := zeroformatter.MapField[string, []string](v, "Config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Len() != len(vSt.Config) {
+		t.Error("length different", config.Len())
+	}
+	for k, e := range vSt.Config {
+		r, ok, err := config.Get(k)
+		if err != nil || !ok || !reflect.DeepEqual(r, e) {
+			t.Error("value different", k, r, ok, err)
+		}
+	}
+	if _, ok, err := config.Get("nothing"); err != nil || ok {
+		t.Error("found error", ok, err)
+	}
+
+	byKey, err := zeroformatter.MapField[key, time.Duration](v, "ByKey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok, err := byKey.Get(key{2, "b"}); err != nil || !ok || r != time.Minute {
+		t.Error("value different", r, ok, err)
+	}
+
+	byTime, err := zeroformatter.MapField[time.Time, int16](v, "ByTime")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok, err := byTime.Get(now.Add(time.Hour).UTC()); err != nil || !ok || r != 2 {
+		t.Error("value different", r, ok, err)
+	}
+
+	// serialized map
+	b, err = zeroformatter.Serialize(map[int]string{1: "a", 2: "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := zeroformatter.NewLazyMap[int, string](b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok, err := m.Get(2); err != nil || !ok || r != "b" {
+		t.Error("value different", r, ok, err)
+	}
+
+	// error
+	if _, err := zeroformatter.MapField[int, []string](v, "Config"); err == nil {
+		t.Error("type error")
+	}
+	broken, err := zeroformatter.NewLazyMap[int, string](b[:len(b)-1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// scanning stops at the found key, so the key which is not found reads all entries
+	if _, _, err := broken.Get(3); err == nil {
+		t.Error("data error")
+	}
+	if _, _, err := broken.Get(3); err == nil {
+		t.Error("index error")
+	}
+	if _, err := zeroformatter.NewLazyMap[string, int]([]byte{0xff, 0xff, 0xff, 0x7f}); err == nil {
+		t.Error("length error")
+	}
+}
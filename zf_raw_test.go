@@ -0,0 +1,118 @@
+package zeroformatter_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shamaton/zeroformatter"
+)
+
+func TestRaw(t *testing.T) {
+	type payload struct {
+		Values map[string][]int
+		Time   time.Time
+	}
+	type message struct {
+		Route   string
+		Payload payload
+		ID      int64
+		Extra   []string
+	}
+	type gateway struct {
+		Route   string
+		Payload zeroformatter.Raw
+		ID      int64
+		Extra   zeroformatter.Raw
+	}
+	vMsg := message{
+		Route:   "route/to",
+		Payload: payload{Values: map[string][]int{"a": {1, 2}}, Time: now},
+		ID:      123,
+		Extra:   []string{"x"},
+	}
+	b, err := zeroformatter.Serialize(vMsg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// pass through
+	g := gateway{}
+	if err := zeroformatter.Deserialize(&g, b); err != nil {
+		t.Fatal(err)
+	}
+	if g.Route != vMsg.Route || g.ID != vMsg.ID {
+		t.Error("value different", g)
+	}
+	p, err := zeroformatter.Serialize(vMsg.Payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// NOTE : nested struct does not have the object header
+	if !reflect.DeepEqual([]byte(g.Payload), p[16:]) {
+		t.Error("raw different", g.Payload, p)
+	}
+	r, err := zeroformatter.Serialize(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r, b) {
+		t.Error("data different", r, b)
+	}
+	rMsg := message{}
+	if err := zeroformatter.Deserialize(&rMsg, r); err != nil || !reflect.DeepEqual(rMsg, vMsg) {
+		t.Error("value different", rMsg, err)
+	}
+
+	// view
+	v, err := zeroformatter.NewView[gateway](b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw, err := v.Field("Payload"); err != nil || !reflect.DeepEqual(raw, g.Payload) {
+		t.Error("raw different", raw, err)
+	}
+
+	// delay
+	dg := gateway{}
+	dds, err := zeroformatter.DelayDeserialize(&dg, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dds.DeserializeByElement(&dg.Extra); err != nil || !reflect.DeepEqual(dg.Extra, g.Extra) {
+		t.Error("raw different", dg.Extra, err)
+	}
+	if r, err := dds.Reserialize(); err != nil || !reflect.DeepEqual(r, b) {
+		t.Error("data different", err)
+	}
+
+	// top level
+	var top zeroformatter.Raw
+	if err := zeroformatter.Deserialize(&top, p); err != nil || !reflect.DeepEqual([]byte(top), p) {
+		t.Error("raw different", top, err)
+	}
+
+	// nested raw is not supported
+	type nested struct {
+		Route   string
+		Payload struct {
+			Raw zeroformatter.Raw
+		}
+		ID    int64
+		Extra zeroformatter.Raw
+	}
+	if err := zeroformatter.Deserialize(&nested{}, b); err == nil {
+		t.Error("nested error")
+	}
+	n := nested{}
+	n.Payload.Raw = zeroformatter.Raw{1, 2, 3}
+	if _, err := zeroformatter.Serialize(n); err == nil {
+		t.Error("nested error")
+	}
+	if _, err := zeroformatter.Serialize([]zeroformatter.Raw{{1}}); err == nil {
+		t.Error("list error")
+	}
+	if r, err := zeroformatter.Serialize(top); err != nil || !reflect.DeepEqual(r, p) {
+		t.Error("top level different", r, err)
+	}
+}
@@ -0,0 +1,161 @@
+package zeroformatter_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shamaton/zeroformatter"
+	"github.com/shamaton/zeroformatter/char"
+	"github.com/shamaton/zeroformatter/datetimeoffset"
+)
+
+const testSchema = `
+// message for test
+object Message {
+	1: String Name
+	0: Int32 ID
+	2: List<Child> Children
+	3: Dictionary<String, DateTimeOffset> Updated
+	4: Dictionary<Int16, List<Double>> Scores;
+}
+
+object Child {
+	0: Char Initial
+	1: TimeSpan Elapsed
+	2: DateTime At
+}
+`
+
+func TestDynamicCodec(t *testing.T) {
+	s, err := zeroformatter.ParseSchema(testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := s.Names(); !reflect.DeepEqual(names, []string{"Child", "Message"}) {
+		t.Error("names different", names)
+	}
+	c, err := s.Codec("Message")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Type().NumField() != 5 {
+		t.Error("type different", c.Type())
+	}
+
+	now := time.Unix(1500000000, 123).UTC()
+	offset := datetimeoffset.UnixOffset(1500000000, 0, -300)
+	v := map[string]interface{}{
+		"ID":   42,
+		"Name": "message",
+		"Children": []interface{}{
+			map[string]interface{}{"Initial": char.Char('a'), "Elapsed": 3 * time.Second, "At": now},
+			map[string]interface{}{"Initial": 0x3042},
+		},
+		"Updated": map[string]interface{}{"first": offset},
+		"Scores":  map[int]interface{}{7: []float64{1.5, 2.5}},
+	}
+	b, err := c.Encode(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// as same as defined struct
+	type child struct {
+		Initial char.Char
+		Elapsed time.Duration
+		At      time.Time
+	}
+	type message struct {
+		ID       int32
+		Name     string
+		Children []child
+		Updated  map[string]datetimeoffset.DateTimeOffset
+		Scores   map[int16][]float64
+	}
+	e, err := zeroformatter.Serialize(message{
+		ID:       42,
+		Name:     "message",
+		Children: []child{{Initial: 'a', Elapsed: 3 * time.Second, At: now}, {Initial: 0x3042}},
+		Updated:  map[string]datetimeoffset.DateTimeOffset{"first": offset},
+		Scores:   map[int16][]float64{7: {1.5, 2.5}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, e) {
+		t.Fatal("bytes different", b, e)
+	}
+
+	r, err := c.Decode(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r["ID"] != int32(42) || r["Name"] != "message" {
+		t.Error("value different", r)
+	}
+	children := r["Children"].([]interface{})
+	c0 := children[0].(map[string]interface{})
+	if len(children) != 2 || c0["Initial"] != char.Char('a') || c0["Elapsed"] != 3*time.Second || !c0["At"].(time.Time).Equal(now) {
+		t.Error("children different", children)
+	}
+	if u := r["Updated"].(map[string]interface{})["first"].(datetimeoffset.DateTimeOffset); !u.Equal(offset.Time) || u.OffsetMinutes() != -300 {
+		t.Error("dictionary different", u)
+	}
+	if sc := r["Scores"].(map[interface{}]interface{})[int16(7)]; !reflect.DeepEqual(sc, []interface{}{1.5, 2.5}) {
+		t.Error("dictionary different", sc)
+	}
+
+	// round trip
+	b2, err := c.Encode(r)
+	if err != nil || !bytes.Equal(b2, e) {
+		t.Error("round trip different", err)
+	}
+
+	// encode errors
+	errs := []map[string]interface{}{
+		{"ID": int64(1) << 40},
+		{"ID": 1.5},
+		{"Name": 1},
+		{"Children": "child"},
+		{"Undefined": 1},
+		{"Scores": map[int]interface{}{1 << 20: nil}},
+	}
+	for _, v := range errs {
+		if _, err := c.Encode(v); err == nil {
+			t.Error("encode must be error", v)
+		}
+	}
+	if _, err := c.Decode(e[:len(e)-1]); err == nil {
+		t.Error("decode must be error")
+	}
+	if _, err := s.Codec("Undefined"); err == nil {
+		t.Error("undefined object must be error")
+	}
+}
+
+func TestParseSchemaError(t *testing.T) {
+	schemas := map[string]string{
+		"undefined type":  "object A { 0: Undefined B }",
+		"index gap":       "object A { 0: Int32 B\n 2: Int32 C }",
+		"duplicate index": "object A { 0: Int32 B\n 0: Int32 C }",
+		"duplicate name":  "object A { 0: Int32 B\n 1: Int32 B }",
+		"duplicate type":  "object A { 0: Int32 B }\nobject A { 0: Int32 B }",
+		"reserved name":   "object String { 0: Int32 B }",
+		"recursive":       "object A { 0: List<B> B }\nobject B { 0: A A }",
+		"list args":       "object A { 0: List<Int32, Int32> B }",
+		"dictionary key":  "object A { 0: Dictionary<List<Int32>, Int32> B }",
+		"primitive args":  "object A { 0: Int32<String> B }",
+		"not closed":      "object A { 0: Int32 B",
+		"syntax":          "message A { 0: Int32 B }",
+	}
+	for name, text := range schemas {
+		if _, err := zeroformatter.ParseSchema(text); err == nil {
+			t.Error(name, "must be error")
+		} else if !strings.HasPrefix(err.Error(), "line ") {
+			t.Error(name, "error has no line", err)
+		}
+	}
+}
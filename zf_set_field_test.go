@@ -0,0 +1,89 @@
+package zeroformatter_test
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shamaton/zeroformatter"
+)
+
+func TestSetField(t *testing.T) {
+	type st struct {
+		Name    string
+		Counter int32
+		Updated time.Time
+		Ratio   float64
+		Enabled bool
+	}
+	vSt := st{Name: "cache", Counter: 1, Updated: now, Ratio: 0.5}
+	b, err := zeroformatter.Serialize(vSt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// by index
+	later := now.Add(time.Minute)
+	if err := zeroformatter.SetField(b, 1, int32(2)); err != nil {
+		t.Error(err)
+	}
+	if err := zeroformatter.SetField(b, 2, later); err != nil {
+		t.Error(err)
+	}
+	if err := zeroformatter.SetField(b, 4, true); err != nil {
+		t.Error(err)
+	}
+
+	// by view
+	v, err := zeroformatter.NewView[st](b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Set("Ratio", 0.75); err != nil {
+		t.Error(err)
+	}
+
+	rSt := st{}
+	if err := zeroformatter.Deserialize(&rSt, b); err != nil {
+		t.Fatal(err)
+	}
+	eSt := st{Name: "cache", Counter: 2, Updated: later, Ratio: 0.75, Enabled: true}
+	if !reflect.DeepEqual(rSt, eSt) {
+		t.Error("value different", rSt, eSt)
+	}
+
+	// error
+	if err := zeroformatter.SetField(b, 1, int64(2)); err == nil {
+		t.Error("size error")
+	}
+	if err := zeroformatter.SetField(b, 0, "name"); err == nil {
+		t.Error("type error")
+	}
+	if err := zeroformatter.SetField(b, 5, true); err == nil {
+		t.Error("index error")
+	}
+	if err := v.Set("Counter", 3); err == nil {
+		t.Error("type error")
+	}
+	if err := v.Set("Name", "name"); err == nil {
+		t.Error("type error")
+	}
+
+	// offset in the header
+	type pair struct {
+		A, B int32
+	}
+	p, err := zeroformatter.Serialize(pair{A: 1, B: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary.LittleEndian.PutUint32(p[8:], 0)
+	pv, err := zeroformatter.NewView[pair](p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pv.Set("A", int32(7)); err == nil || binary.LittleEndian.Uint32(p) != uint32(len(p)) {
+		t.Error("offset error", p, err)
+	}
+}
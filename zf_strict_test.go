@@ -0,0 +1,122 @@
+package zeroformatter_test
+
+import (
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shamaton/zeroformatter"
+)
+
+func TestStrict(t *testing.T) {
+	strict := zeroformatter.WithStrict()
+
+	type st struct {
+		Flag bool
+		Name string
+		List []int16
+	}
+	v := st{Flag: true, Name: "zf", List: []int16{1, 2}}
+	b, err := zeroformatter.Serialize(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := st{}
+	if err := zeroformatter.Deserialize(&r, b, strict); err != nil || r.Name != "zf" {
+		t.Error(err, r)
+	}
+	// Flag:20 Name:21 List:27
+
+	tests := []struct {
+		name string
+		edit func(b []byte) []byte
+		err  string
+	}{
+		{"bool", func(b []byte) []byte {
+			b[20] = 2
+			return b
+		}, "bool value is not 0 or 1"},
+		{"inside header", func(b []byte) []byte {
+			binary.LittleEndian.PutUint32(b[8:], 16)
+			return b
+		}, "offset of index 0 is not contiguous"},
+		{"overlap", func(b []byte) []byte {
+			binary.LittleEndian.PutUint32(b[16:], 25)
+			return b
+		}, "offset of index 2 is not contiguous"},
+		{"gap", func(b []byte) []byte {
+			// Name is "z" and 1 byte is not used
+			binary.LittleEndian.PutUint32(b[21:], 1)
+			return b
+		}, "offset of index 2 is not contiguous"},
+		{"trailing", func(b []byte) []byte {
+			b = append(b, 0)
+			binary.LittleEndian.PutUint32(b, uint32(len(b)))
+			return b
+		}, "1 bytes are not used after the last field"},
+	}
+	for _, tt := range tests {
+		data := tt.edit(append([]byte{}, b...))
+		// lenient by default
+		if err := zeroformatter.Deserialize(&st{}, data); err != nil && tt.name != "inside header" && tt.name != "overlap" {
+			t.Error(tt.name, "must not be error without strict", err)
+		}
+		if err := zeroformatter.Deserialize(&st{}, data, strict); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Error(tt.name, "error different", err)
+		}
+	}
+
+	// view and codec also check
+	b[20] = 2
+	if _, err := zeroformatter.Unmarshal[st](b, strict); err == nil {
+		t.Error("unmarshal must be error")
+	}
+	b[20] = 1
+	binary.LittleEndian.PutUint32(b[16:], 25)
+	if _, err := zeroformatter.NewView[st](b, strict); err == nil {
+		t.Error("view must be error")
+	}
+
+	// top level value
+	p, _ := zeroformatter.Serialize([]string{"a"})
+	if err := zeroformatter.Deserialize(&[]string{}, append(p, 0), strict); err == nil || err.Error() != "1 bytes remain after the value" {
+		t.Error("trailing bytes must be error", err)
+	}
+	if err := zeroformatter.Deserialize(&[]string{}, append(p, 0)); err != nil {
+		t.Error(err)
+	}
+
+	// empty maps are read to the end
+	p, _ = zeroformatter.Serialize(map[string]int{})
+	if err := zeroformatter.Deserialize(&map[string]int{}, p, strict); err != nil {
+		t.Error(err)
+	}
+	maps := []map[string]int{{}, {"a": 1}}
+	p, _ = zeroformatter.Serialize(maps)
+	rMaps := []map[string]int{}
+	if err := zeroformatter.Deserialize(&rMaps, p, strict); err != nil || !reflect.DeepEqual(rMaps, maps) {
+		t.Error("maps different", rMaps, err)
+	}
+	if err := zeroformatter.Deserialize(&rMaps, append(p, 0), strict); err == nil || err.Error() != "1 bytes remain after the value" {
+		t.Error("trailing bytes must be error", err)
+	}
+
+	// raw and unknown end at the next offset
+	type raw struct {
+		Flag bool
+		Name zeroformatter.Raw
+		List []int16
+	}
+	type unknown struct {
+		Flag    bool
+		Unknown zeroformatter.Unknown
+	}
+	binary.LittleEndian.PutUint32(b[16:], 27)
+	if err := zeroformatter.Deserialize(&raw{}, b, strict); err != nil {
+		t.Error(err)
+	}
+	if err := zeroformatter.Deserialize(&unknown{}, b, strict); err != nil {
+		t.Error(err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -112,6 +113,46 @@ func TestPrimitiveInt(t *testing.T) {
 	}
 }
 
+func TestIntOption(t *testing.T) {
+	// NOTE : int and uint can be out of range of Int32 only on 64 bit platforms
+	if strconv.IntSize == 64 {
+		big := int64(math.MaxInt32) + 1
+		if _, err := zeroformatter.Serialize(int(big), zeroformatter.WithCheckedInt()); err == nil {
+			t.Error("range error")
+		}
+		if _, err := zeroformatter.Serialize(int(-big-1), zeroformatter.WithCheckedInt()); err == nil {
+			t.Error("range error")
+		}
+		bigUint := uint64(math.MaxUint32) + 1
+		if _, err := zeroformatter.Serialize(uint(bigUint), zeroformatter.WithCheckedInt()); err == nil {
+			t.Error("range error")
+		}
+	}
+	if _, err := zeroformatter.Serialize([]int{math.MinInt32, math.MaxInt32}, zeroformatter.WithCheckedInt()); err != nil {
+		t.Error(err)
+	}
+
+	type st struct {
+		Int  int
+		Uint uint
+		Ints []int
+	}
+	// converted at runtime, so they are truncated on 32 bit platforms
+	minInt, maxInt, maxUint := int64(math.MinInt64), int64(math.MaxInt64), uint64(math.MaxUint64)
+	vSt := st{Int: int(minInt), Uint: uint(maxUint), Ints: []int{int(maxInt), -1}}
+	d, err := zeroformatter.Serialize(vSt, zeroformatter.WithInt64())
+	if err != nil {
+		t.Error(err)
+	}
+	rSt := st{}
+	if err := zeroformatter.Deserialize(&rSt, d, zeroformatter.WithInt64()); err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(vSt, rSt) {
+		t.Error("value different", vSt, rSt)
+	}
+}
+
 func TestPrimitiveUint(t *testing.T) {
 	var rUint8 uint8
 	vUint8 := uint8(math.MaxUint8)
@@ -304,6 +345,37 @@ func TestPrimitiveString(t *testing.T) {
 	}
 }
 
+func TestChar(t *testing.T) {
+	// out of BMP
+	vChar := char.Char('😀')
+	if _, err := zeroformatter.Serialize(vChar); err == nil {
+		t.Error("char error")
+	}
+
+	var rChar char.Char
+	d, err := zeroformatter.Serialize(vChar, zeroformatter.WithLenientChar())
+	if err != nil {
+		t.Error(err)
+	}
+	if err := zeroformatter.Deserialize(&rChar, d); err != nil || rChar != char.ReplacementChar {
+		t.Error("char different", err, rChar)
+	}
+
+	// surrogate pair
+	str := "ZeroFormatter😀ゼロ"
+	vChars := char.FromString(str)
+	if len(vChars) != len("ZeroFormatter")+2+2 {
+		t.Error("length different", len(vChars))
+	}
+	var rChars []char.Char
+	if err := checkRoutine(t, vChars, &rChars, false); err != nil {
+		t.Error(err)
+	}
+	if char.ToString(rChars) != str {
+		t.Error("string different", char.ToString(rChars))
+	}
+}
+
 func TestPrimitiveTime(t *testing.T) {
 
 	var rTime time.Time
@@ -318,6 +390,12 @@ func TestPrimitiveTime(t *testing.T) {
 		t.Error(err)
 	}
 
+	rNegative := time.Duration(0)
+	vNegative := -time.Duration(12*time.Hour + 78*time.Nanosecond)
+	if err := checkRoutine(t, vNegative, &rNegative, false); err != nil {
+		t.Error(err)
+	}
+
 	var rOffset datetimeoffset.DateTimeOffset
 	vOffset := datetimeoffset.Now()
 	if err := checkRoutine(t, vOffset, &rOffset, false); err != nil {
@@ -355,6 +433,18 @@ func TestPrimitiveTime(t *testing.T) {
 		t.Error(err)
 	}
 
+	// offset
+	for _, loc := range []*time.Location{time.UTC, time.FixedZone("EST", -5*60*60), time.FixedZone("IST", 5*60*60+30*60)} {
+		var rOffsetZone datetimeoffset.DateTimeOffset
+		vOffsetZone := datetimeoffset.FromTime(now).In(loc)
+		if err := checkRoutine(t, vOffsetZone, &rOffsetZone, false); err != nil {
+			t.Error(err)
+		}
+		if rOffsetZone.Offset() != vOffsetZone.Offset() || !rOffsetZone.Equal(now) {
+			t.Error("offset different", loc, rOffsetZone)
+		}
+	}
+
 	// error
 	var rError time.Time
 	vError := datetimeoffset.Now()
@@ -365,6 +455,27 @@ func TestPrimitiveTime(t *testing.T) {
 	}
 }
 
+func TestTimeLocation(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	before := time.Date(1969, 12, 31, 23, 59, 59, 123456789, jst)
+
+	for _, v := range []time.Time{now, now.In(jst), before} {
+		d, err := zeroformatter.Serialize(v)
+		if err != nil {
+			t.Error(err)
+		}
+		for _, loc := range []*time.Location{time.UTC, time.Local, jst} {
+			var r time.Time
+			if err := zeroformatter.Deserialize(&r, d, zeroformatter.WithLocation(loc)); err != nil {
+				t.Error(err)
+			}
+			if !r.Equal(v) || r.Location() != loc {
+				t.Error("time different", v, r)
+			}
+		}
+	}
+}
+
 func TestArray(t *testing.T) {
 
 	var rIntA [10]int
@@ -581,6 +692,29 @@ func TestMap(t *testing.T) {
 	if err := checkRoutine(t, rMapStr, &vMapStr, false); err != nil {
 		t.Error(err)
 	}
+
+	rMapNested := map[int16][]map[string]int{1: {{"a": 1, "b": 2}, {"c": 3}}, 2: {}, 3: {{}}}
+	vMapNested := map[int16][]map[string]int{}
+	if err := checkRoutine(t, rMapNested, &vMapNested, false); err != nil {
+		t.Error(err)
+	}
+
+	// empty map is followed by the next element
+	rMapEmpty := []map[string]int{{}, {"a": 1}, {}}
+	vMapEmpty := []map[string]int{}
+	if err := checkRoutine(t, rMapEmpty, &vMapEmpty, false); err != nil {
+		t.Error(err)
+	}
+
+	rMapNaN := map[float64]int8{math.NaN(): 1, 1.5: 2}
+	b, err := zeroformatter.Serialize(rMapNaN)
+	if err != nil {
+		t.Error(err)
+	}
+	vMapNaN := map[float64]int8{}
+	if err := zeroformatter.Deserialize(&vMapNaN, b); err != nil || len(vMapNaN) != 2 || vMapNaN[1.5] != 2 {
+		t.Error("NaN key map different", vMapNaN, err)
+	}
 }
 
 // for test
@@ -0,0 +1,103 @@
+package zeroformatter_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shamaton/zeroformatter"
+)
+
+func TestUnknown(t *testing.T) {
+	type v1 struct {
+		Name    string
+		Count   int32
+		Unknown zeroformatter.Unknown
+	}
+	type v1Strict struct {
+		Name  string
+		Count int32
+	}
+	type v2 struct {
+		Name  string
+		Count int32
+		Tags  []string
+		Time  time.Time
+	}
+	vNew := v2{Name: "new", Count: 2, Tags: []string{"a", "b"}, Time: now}
+	b, err := zeroformatter.Serialize(vNew)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// relay
+	old := v1{}
+	if err := zeroformatter.Deserialize(&old, b); err != nil {
+		t.Fatal(err)
+	}
+	if old.Name != vNew.Name || old.Count != vNew.Count || old.Unknown.Len() != 2 {
+		t.Error("value different", old)
+	}
+	old.Count++
+	r, err := zeroformatter.Serialize(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rNew := v2{}
+	if err := zeroformatter.Deserialize(&rNew, r); err != nil {
+		t.Fatal(err)
+	}
+	eNew := vNew
+	eNew.Count++
+	if !reflect.DeepEqual(rNew, eNew) {
+		t.Error("value different", rNew, eNew)
+	}
+
+	// delay
+	dOld := v1{}
+	dds, err := zeroformatter.DelayDeserialize(&dOld, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dOld.Unknown.Len() != 2 || dds.Remaining() != 2 {
+		t.Error("value different", dOld)
+	}
+	if r, err := dds.Reserialize(); err != nil || !reflect.DeepEqual(r, b) {
+		t.Error("data different", err)
+	}
+
+	// view
+	v, err := zeroformatter.NewView[v1](b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.NumField() != 2 {
+		t.Error("num field different", v.NumField())
+	}
+	if _, err := v.Field("Unknown"); err == nil {
+		t.Error("field error")
+	}
+
+	// same version
+	b, err = zeroformatter.Serialize(v1{Name: "same"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	same := v1{}
+	if err := zeroformatter.Deserialize(&same, b); err != nil || !reflect.DeepEqual(same, v1{Name: "same"}) {
+		t.Error("value different", same, err)
+	}
+	strict := v1Strict{}
+	if err := zeroformatter.Deserialize(&strict, b); err != nil || strict.Name != "same" {
+		t.Error("value different", strict, err)
+	}
+
+	// without Unknown
+	b, err = zeroformatter.Serialize(vNew)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := zeroformatter.Deserialize(&strict, b); err == nil {
+		t.Error("index error")
+	}
+}
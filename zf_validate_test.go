@@ -0,0 +1,124 @@
+package zeroformatter_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/shamaton/zeroformatter"
+)
+
+type validateColor uint8
+
+func TestValidate(t *testing.T) {
+	if err := zeroformatter.RegisterEnum(reflect.TypeOf(validateColor(0)), reflect.Uint8, validateColor(1), validateColor(2)); err != nil {
+		t.Fatal(err)
+	}
+
+	type child struct {
+		Flags []bool
+		Color validateColor
+	}
+	type st struct {
+		ID    int32
+		Name  string
+		Child child
+		Map   map[int16]string
+	}
+	v := st{ID: 1, Name: "zf", Child: child{Flags: []bool{true, false}, Color: 2}, Map: map[int16]string{1: "a"}}
+	b, err := zeroformatter.Serialize(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := zeroformatter.Validate(b, reflect.TypeOf(&v)); err != nil {
+		t.Error(err)
+	}
+	// ID:24 Name:28 Flags:34 Color:40 Map:41 Map[0][value]:47
+
+	tests := []struct {
+		name  string
+		edit  func(b []byte) []byte
+		paths []string
+	}{
+		{"size", func(b []byte) []byte { return append(b, 0) }, []string{"zeroformatter_test.st"}},
+		{"last index", func(b []byte) []byte {
+			binary.LittleEndian.PutUint32(b[4:], 2)
+			return b
+		}, []string{"zeroformatter_test.st"}},
+		{"offset range", func(b []byte) []byte {
+			binary.LittleEndian.PutUint32(b[12:], 1000)
+			return b
+		}, []string{"zeroformatter_test.st.Name"}},
+		{"offset order", func(b []byte) []byte {
+			binary.LittleEndian.PutUint32(b[20:], 30)
+			return b
+		}, []string{"zeroformatter_test.st.Map"}},
+		{"overlap", func(b []byte) []byte {
+			binary.LittleEndian.PutUint32(b[16:], 30)
+			return b
+		}, []string{"zeroformatter_test.st.Name", "zeroformatter_test.st.Child.Flags"}},
+		{"string length", func(b []byte) []byte {
+			binary.LittleEndian.PutUint32(b[28:], 100)
+			return b
+		}, []string{"zeroformatter_test.st.Name"}},
+		{"bool and enum", func(b []byte) []byte {
+			b[39] = 2
+			b[40] = 3
+			return b
+		}, []string{"zeroformatter_test.st.Child.Flags[1]", "zeroformatter_test.st.Child.Color"}},
+		{"list length", func(b []byte) []byte {
+			binary.LittleEndian.PutUint32(b[34:], 20)
+			return b
+		}, []string{"zeroformatter_test.st.Child.Flags"}},
+		{"map", func(b []byte) []byte {
+			binary.LittleEndian.PutUint32(b[41:], 0xffffffff)
+			return b
+		}, []string{"zeroformatter_test.st.Map"}},
+		{"map value", func(b []byte) []byte {
+			binary.LittleEndian.PutUint32(b[47:], 2)
+			return b
+		}, []string{"zeroformatter_test.st.Map[0][value]"}},
+	}
+	for _, tt := range tests {
+		err := zeroformatter.Validate(tt.edit(append([]byte{}, b...)), reflect.TypeOf(v))
+		var ve *zeroformatter.ValidationError
+		if !errors.As(err, &ve) {
+			t.Error(tt.name, "must be error", err)
+			continue
+		}
+		paths := []string{}
+		for _, p := range ve.Problems {
+			paths = append(paths, p.Path)
+		}
+		if !reflect.DeepEqual(paths, tt.paths) {
+			t.Error(tt.name, "problems different", ve)
+		}
+	}
+
+	// short data
+	for i := 0; i < len(b); i++ {
+		if err := zeroformatter.Validate(b[:i], reflect.TypeOf(v)); err == nil {
+			t.Error("short data must be error", i)
+		}
+	}
+
+	// not object
+	b, _ = zeroformatter.Serialize([]string{"a", "b"})
+	if err := zeroformatter.Validate(b, reflect.TypeOf([]string{})); err != nil {
+		t.Error(err)
+	}
+	if err := zeroformatter.Validate(b[:len(b)-1], reflect.TypeOf([]string{})); err == nil {
+		t.Error("short list must be error")
+	}
+	err = zeroformatter.Validate(append(b, 0, 0), reflect.TypeOf([]string{}))
+	var ve *zeroformatter.ValidationError
+	if !errors.As(err, &ve) || len(ve.Problems) != 1 || ve.Problems[0].Offset != uint32(len(b)) {
+		t.Error("trailing bytes must be error", err)
+	}
+
+	// raw is byte data as it is
+	if err := zeroformatter.Validate([]byte{1, 2, 3}, reflect.TypeOf(zeroformatter.Raw{})); err != nil {
+		t.Error(err)
+	}
+}
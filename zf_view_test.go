@@ -0,0 +1,104 @@
+package zeroformatter_test
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shamaton/zeroformatter"
+	"github.com/shamaton/zeroformatter/char"
+)
+
+func TestView(t *testing.T) {
+	type child struct {
+		Int   int
+		Chars []char.Char
+	}
+	type st struct {
+		Int32  int32
+		String string
+		Time   time.Time
+		Child  child
+		Map    map[string]int
+	}
+	vSt := st{
+		Int32:  -32,
+		String: "route",
+		Time:   now,
+		Child:  child{Int: 1, Chars: []char.Char{'a', 'b'}},
+		Map:    map[string]int{"a": 1},
+	}
+	b, err := zeroformatter.Serialize(vSt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := zeroformatter.NewView[st](b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.NumField() != 5 {
+		t.Error("num field different", v.NumField())
+	}
+
+	// by name
+	s, err := v.Field("String")
+	if err != nil || s != vSt.String {
+		t.Error("value different", s, err)
+	}
+	c, err := v.Field("Child")
+	if err != nil || !reflect.DeepEqual(c, vSt.Child) {
+		t.Error("value different", c, err)
+	}
+
+	// by index
+	i, err := v.FieldByIndex(0)
+	if err != nil || i != vSt.Int32 {
+		t.Error("value different", i, err)
+	}
+
+	// into holder
+	var m map[string]int
+	if err := v.Decode("Map", &m); err != nil || !reflect.DeepEqual(m, vSt.Map) {
+		t.Error("value different", m, err)
+	}
+	var tm time.Time
+	if err := v.Decode("Time", &tm); err != nil || !tm.Equal(now) {
+		t.Error("value different", tm, err)
+	}
+
+	// error
+	if _, err := v.Field("Nothing"); err == nil {
+		t.Error("name error")
+	}
+	if _, err := v.FieldByIndex(5); err == nil {
+		t.Error("index error")
+	}
+	var wrong string
+	if err := v.Decode("Int32", &wrong); err == nil {
+		t.Error("type error")
+	}
+	if _, err := zeroformatter.NewView[child](b); err == nil {
+		t.Error("header error")
+	}
+	if _, err := zeroformatter.NewView[time.Time](b); err == nil {
+		t.Error("type error")
+	}
+
+	// corrupted offsets
+	broken := append([]byte{}, b...)
+	binary.LittleEndian.PutUint32(broken[8:], uint32(len(broken)+100))
+	binary.LittleEndian.PutUint32(broken[12:], uint32(len(broken)-2))
+	bv, err := zeroformatter.NewView[st](broken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bv.FieldByIndex(0); err == nil {
+		t.Error("offset error")
+	}
+	var str string
+	if err := bv.Decode("String", &str); err == nil {
+		t.Error("length error")
+	}
+}